@@ -0,0 +1,282 @@
+package cache
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/semirm-dev/findhotel/geo"
+	"hash/fnv"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// tightening is error rate ratio of each next bloom filter layer, it keeps overall error rate below 2x given one
+const tightening = 0.5
+
+// layer is a single bloom filter, when it's full the next twice as big layer is added
+type layer struct {
+	Bits     []uint64
+	M        uint64
+	K        uint64
+	Capacity uint64
+	Count    uint64
+}
+
+// snapshot is persisted bloom filter, it can be restored only by filter sized the same way
+type snapshot struct {
+	Capacity  uint64
+	ErrorRate float64
+	Layers    []*layer
+}
+
+type bloom struct {
+	mu        sync.Mutex
+	layers    []*layer
+	capacity  uint64
+	errorRate float64
+	// Confirm (optional) is asked if probable duplicates are really stored, to rule out false positives
+	Confirm geo.Search
+}
+
+// NewBloom will initialize in-process scalable bloom filter Cache, memory use is bounded by number of stored keys
+// and not by their size. Capacity is number of keys in the first layer, errorRate is false positive rate (0-1).
+func NewBloom(capacity int, errorRate float64) (*bloom, error) {
+	if err := validateBloom(capacity, errorRate); err != nil {
+		return nil, err
+	}
+
+	b := &bloom{
+		capacity:  uint64(capacity),
+		errorRate: errorRate,
+	}
+	b.addLayer()
+
+	return b, nil
+}
+
+func (b *bloom) Store(ctx context.Context, items geo.CacheBucket) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for k := range items {
+		l := b.layers[len(b.layers)-1]
+		if l.Count >= l.Capacity {
+			l = b.addLayer()
+		}
+
+		for _, p := range positions(k, l.M, l.K) {
+			l.Bits[p/64] |= 1 << (p % 64)
+		}
+		l.Count++
+	}
+
+	return nil
+}
+
+func (b *bloom) Get(ctx context.Context, keys []string) (geo.KeySet, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	probable := make([]string, 0)
+	for _, k := range keys {
+		if b.contains(k) {
+			probable = append(probable, k)
+		}
+	}
+	b.mu.Unlock()
+
+	return confirm(ctx, b.Confirm, probable)
+}
+
+// Purge will remove all keys
+func (b *bloom) Purge() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.layers = nil
+	b.addLayer()
+
+	return nil
+}
+
+// Snapshot will atomically write all filter layers to file at path, together with capacity and error rate
+func (b *bloom) Snapshot(path string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	snap := &snapshot{
+		Capacity:  b.capacity,
+		ErrorRate: b.errorRate,
+		Layers:    b.layers,
+	}
+	if err = gob.NewEncoder(tmp).Encode(snap); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Restore will replace filter layers with ones from snapshot file at path, missing file is not an error.
+// Snapshot of filter with different capacity or error rate is rejected, its layers would not be sized as configured.
+func (b *bloom) Restore(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	var snap snapshot
+	if err = gob.NewDecoder(f).Decode(&snap); err != nil {
+		return fmt.Errorf("failed to decode bloom filter snapshot %s: %w", path, err)
+	}
+	if snap.Capacity != b.capacity || snap.ErrorRate != b.errorRate {
+		return fmt.Errorf("bloom filter snapshot %s has capacity %d and error rate %v, configured are %d and %v: "+
+			"remove snapshot to rebuild it, or configure filter as it was", path, snap.Capacity, snap.ErrorRate, b.capacity, b.errorRate)
+	}
+	if len(snap.Layers) == 0 {
+		return errors.New("bloom filter snapshot has no layers")
+	}
+	for i, l := range snap.Layers {
+		m, k := b.layerSize(i)
+		if l.M != m || l.K != k || l.Capacity != b.capacity<<i || uint64(len(l.Bits)) != (l.M+63)/64 {
+			return errors.New("bloom filter snapshot has invalid layer")
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.layers = snap.Layers
+
+	return nil
+}
+
+func (b *bloom) contains(key string) bool {
+	for _, l := range b.layers {
+		if l.contains(key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (b *bloom) addLayer() *layer {
+	i := len(b.layers)
+	m, k := b.layerSize(i)
+
+	l := &layer{
+		Bits:     make([]uint64, (m+63)/64),
+		M:        m,
+		K:        k,
+		Capacity: b.capacity << i,
+	}
+	b.layers = append(b.layers, l)
+
+	return l
+}
+
+// layerSize returns number of bits (m) and hash functions (k) of i-th layer
+func (b *bloom) layerSize(i int) (uint64, uint64) {
+	return optimal(b.capacity<<i, b.errorRate*math.Pow(tightening, float64(i)))
+}
+
+func (l *layer) contains(key string) bool {
+	for _, p := range positions(key, l.M, l.K) {
+		if l.Bits[p/64]&(1<<(p%64)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateBloom returns error if bloom filter can't be sized for capacity keys with given false positive rate
+func validateBloom(capacity int, errorRate float64) error {
+	if capacity <= 0 {
+		return fmt.Errorf("bloom filter capacity must be positive, got %d", capacity)
+	}
+	// NaN fails both comparisons
+	if !(errorRate > 0 && errorRate < 1) {
+		return fmt.Errorf("bloom filter error rate must be between 0 and 1 (exclusive), got %v", errorRate)
+	}
+
+	return nil
+}
+
+// optimal returns number of bits (m) and hash functions (k) for capacity keys with given false positive rate,
+// capacity and error rate must be valid (see validateBloom)
+func optimal(capacity uint64, errorRate float64) (uint64, uint64) {
+	m := math.Ceil(-float64(capacity) * math.Log(errorRate) / (math.Ln2 * math.Ln2))
+	k := math.Round(m / float64(capacity) * math.Ln2)
+	if k < 1 {
+		k = 1
+	}
+
+	return uint64(m), uint64(k)
+}
+
+// positions returns k bit positions of key, derived from two halves of 128-bit FNV hash (double hashing)
+func positions(key string, m, k uint64) []uint64 {
+	h := fnv.New128a()
+	_, _ = h.Write([]byte(key))
+	sum := h.Sum(nil)
+
+	h1 := binary.BigEndian.Uint64(sum[:8])
+	h2 := binary.BigEndian.Uint64(sum[8:])
+
+	p := make([]uint64, k)
+	for i := uint64(0); i < k; i++ {
+		p[i] = (h1 + i*h2) % m
+	}
+
+	return p
+}
+
+// confirm returns probable keys which are really stored, all of them if there is nothing to confirm with
+func confirm(ctx context.Context, search geo.Search, probable []string) (geo.KeySet, error) {
+	existing := make(geo.KeySet)
+	if search == nil || len(probable) == 0 {
+		for _, k := range probable {
+			existing[k] = struct{}{}
+		}
+		return existing, nil
+	}
+
+	found, err := search.ByIps(ctx, probable)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range probable {
+		// ip can be found in stored network, only exact match is a duplicate
+		if g, ok := found[k]; ok && g.Ip == k {
+			existing[k] = struct{}{}
+		}
+	}
+
+	return existing, nil
+}
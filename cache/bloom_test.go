@@ -0,0 +1,161 @@
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/semirm-dev/findhotel/cache"
+	"github.com/semirm-dev/findhotel/datastore"
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func bucket(from, to int) (geo.CacheBucket, []string) {
+	items := make(geo.CacheBucket)
+	keys := make([]string, 0)
+	for i := from; i < to; i++ {
+		k := fmt.Sprintf("10.%d.%d.%d", i>>16&255, i>>8&255, i&255)
+		items[k] = k
+		keys = append(keys, k)
+	}
+
+	return items, keys
+}
+
+func TestNewBloom_Invalid(t *testing.T) {
+	testData := map[string]struct {
+		capacity  int
+		errorRate float64
+	}{
+		"zero capacity": {
+			capacity:  0,
+			errorRate: 0.01,
+		},
+		"negative capacity": {
+			capacity:  -1,
+			errorRate: 0.01,
+		},
+		"zero error rate": {
+			capacity:  1000,
+			errorRate: 0,
+		},
+		"error rate of 1": {
+			capacity:  1000,
+			errorRate: 1,
+		},
+		"negative error rate": {
+			capacity:  1000,
+			errorRate: -0.01,
+		},
+		"NaN error rate": {
+			capacity:  1000,
+			errorRate: math.NaN(),
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			b, err := cache.NewBloom(td.capacity, td.errorRate)
+			assert.Error(t, err)
+			assert.Nil(t, b)
+
+			rb, err := cache.NewRedisBloom(cache.NewRedisConfig(), td.capacity, td.errorRate)
+			assert.Error(t, err)
+			assert.Nil(t, rb)
+		})
+	}
+}
+
+func TestBloom_Get(t *testing.T) {
+	// capacity is exceeded, so that filter has to grow
+	b, err := cache.NewBloom(1000, 0.01)
+	assert.Nil(t, err)
+
+	stored, storedKeys := bucket(0, 5000)
+	assert.Nil(t, b.Store(context.Background(), stored))
+
+	existing, err := b.Get(context.Background(), storedKeys)
+	assert.Nil(t, err)
+	assert.Len(t, existing, len(storedKeys))
+
+	_, newKeys := bucket(5000, 15000)
+	existing, err = b.Get(context.Background(), newKeys)
+	assert.Nil(t, err)
+	assert.Less(t, len(existing), 200, "false positive rate must stay below 2x error rate")
+}
+
+func TestBloom_Confirm(t *testing.T) {
+	b, err := cache.NewBloom(10, 0.5)
+	assert.Nil(t, err)
+
+	stored, storedKeys := bucket(0, 100)
+	assert.Nil(t, b.Store(context.Background(), stored))
+
+	search := datastore.NewInMemory()
+	_, err = search.Store(context.Background(), []*geo.Geo{{Ip: storedKeys[0]}, {Ip: "10.0.0.0/8"}}, geo.ConflictSkip)
+	assert.Nil(t, err)
+	b.Confirm = search
+
+	_, newKeys := bucket(100, 1000)
+	existing, err := b.Get(context.Background(), append(newKeys, storedKeys[0]))
+	assert.Nil(t, err)
+	assert.Equal(t, geo.KeySet{storedKeys[0]: {}}, existing)
+}
+
+func TestBloom_Snapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bloom.snapshot")
+
+	b, err := cache.NewBloom(100, 0.01)
+	assert.Nil(t, err)
+	assert.Nil(t, b.Restore(path), "missing snapshot is not an error")
+
+	stored, storedKeys := bucket(0, 300)
+	assert.Nil(t, b.Store(context.Background(), stored))
+	assert.Nil(t, b.Snapshot(path))
+
+	restored, err := cache.NewBloom(100, 0.01)
+	assert.Nil(t, err)
+	assert.Nil(t, restored.Restore(path))
+
+	existing, err := restored.Get(context.Background(), storedKeys)
+	assert.Nil(t, err)
+	assert.Len(t, existing, len(storedKeys))
+
+	assert.Nil(t, restored.Purge())
+	existing, err = restored.Get(context.Background(), storedKeys)
+	assert.Nil(t, err)
+	assert.Empty(t, existing)
+}
+
+func TestBloom_Restore_Mismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bloom.snapshot")
+
+	b, err := cache.NewBloom(100, 0.01)
+	assert.Nil(t, err)
+	stored, storedKeys := bucket(0, 10)
+	assert.Nil(t, b.Store(context.Background(), stored))
+	assert.Nil(t, b.Snapshot(path))
+
+	testTable := map[string]struct {
+		capacity  int
+		errorRate float64
+	}{
+		"different capacity":   {capacity: 1000, errorRate: 0.01},
+		"different error rate": {capacity: 100, errorRate: 0.001},
+	}
+
+	for name, tt := range testTable {
+		t.Run(name, func(t *testing.T) {
+			restored, err := cache.NewBloom(tt.capacity, tt.errorRate)
+			assert.Nil(t, err)
+			assert.Error(t, restored.Restore(path))
+
+			// configured filter is kept
+			existing, err := restored.Get(context.Background(), storedKeys)
+			assert.Nil(t, err)
+			assert.Empty(t, existing)
+		})
+	}
+}
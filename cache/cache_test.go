@@ -0,0 +1,138 @@
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/semirm-dev/findhotel/cache"
+	"github.com/semirm-dev/findhotel/datastore"
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/semirm-dev/findhotel/geotest"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"time"
+)
+
+// purgeableCache is geo.Cache which can remove all its keys
+type purgeableCache interface {
+	geo.Cache
+	Purge() error
+}
+
+// testCache will run geo.Cache conformance suite, and check if purged keys don't exist anymore
+func testCache(t *testing.T, newCache func(t *testing.T) purgeableCache) {
+	geotest.TestCache(t, func(t *testing.T) geo.Cache {
+		return newCache(t)
+	})
+
+	t.Run("purged keys don't exist", func(t *testing.T) {
+		c := newCache(t)
+
+		assert.Nil(t, c.Store(context.Background(), geo.CacheBucket{"1.1.1.1": "1.1.1.1"}))
+		assert.Nil(t, c.Purge())
+
+		existing, err := c.Get(context.Background(), []string{"1.1.1.1"})
+		assert.Nil(t, err)
+		assert.Empty(t, existing)
+	})
+}
+
+func TestInMemory_Conformance(t *testing.T) {
+	testCache(t, func(t *testing.T) purgeableCache {
+		return cache.NewInMemory()
+	})
+}
+
+func TestBloom_Conformance(t *testing.T) {
+	testCache(t, func(t *testing.T) purgeableCache {
+		c, err := cache.NewBloom(1000, 0.0001)
+		assert.Nil(t, err)
+
+		return c
+	})
+}
+
+func TestRedis_Conformance(t *testing.T) {
+	testCache(t, func(t *testing.T) purgeableCache {
+		conf := cache.NewRedisConfig()
+		conf.Host, conf.Prefix = redisNamespace(t)
+
+		c := cache.NewRedis(conf)
+		if err := c.Initialize(); err != nil {
+			t.Skipf("redis is not available: %v", err)
+		}
+		t.Cleanup(func() {
+			assert.Nil(t, c.Purge())
+		})
+
+		return c
+	})
+}
+
+func TestRedis_PurgeLegacy(t *testing.T) {
+	conf := cache.NewRedisConfig()
+	conf.Host, conf.Prefix = redisNamespace(t)
+
+	c := cache.NewRedis(conf)
+	if err := c.Initialize(); err != nil {
+		t.Skipf("redis is not available: %v", err)
+	}
+	t.Cleanup(func() {
+		assert.Nil(t, c.Purge())
+	})
+
+	// keys stored before they were namespaced
+	for _, k := range []string{"203.0.113.7", "2001:db8::7", "203.0.113.0/24"} {
+		assert.Nil(t, c.Set(k, k, 0).Err())
+	}
+	assert.Nil(t, c.Store(context.Background(), geo.CacheBucket{"203.0.113.7": "203.0.113.7"}))
+
+	removed, err := c.PurgeLegacy()
+	assert.Nil(t, err)
+	assert.GreaterOrEqual(t, removed, 3)
+
+	for _, k := range []string{"203.0.113.7", "2001:db8::7", "203.0.113.0/24"} {
+		assert.Zero(t, c.Exists(k).Val(), k)
+	}
+	existing, err := c.Get(context.Background(), []string{"203.0.113.7"})
+	assert.Nil(t, err)
+	assert.Len(t, existing, 1)
+}
+
+func TestRedisBloom_Conformance(t *testing.T) {
+	testCache(t, func(t *testing.T) purgeableCache {
+		conf := cache.NewRedisConfig()
+		conf.Host, conf.Prefix = redisNamespace(t)
+
+		c, err := cache.NewRedisBloom(conf, 1000, 0.0001)
+		assert.Nil(t, err)
+		if err = c.Initialize(); err != nil {
+			t.Skipf("redis is not available: %v", err)
+		}
+		t.Cleanup(func() {
+			assert.Nil(t, c.Purge())
+		})
+
+		return c
+	})
+}
+
+func TestLookup_Conformance(t *testing.T) {
+	geotest.TestSearch(t, func(t *testing.T, stored []*geo.Geo) geo.Search {
+		s := datastore.NewInMemory()
+		_, err := s.Store(context.Background(), stored, geo.ConflictSkip)
+		assert.Nil(t, err)
+
+		return cache.NewLookup(s, cache.NewLru(100))
+	})
+}
+
+// redisNamespace returns redis host (REDIS_HOST, localhost by default) and key prefix unique for each test
+func redisNamespace(t *testing.T) (string, string) {
+	host := os.Getenv("REDIS_HOST")
+	if host == "" {
+		host = "localhost"
+	}
+
+	return host, cache.DedupPrefix("test", fmt.Sprintf("%s-%d", t.Name(), time.Now().UnixNano()))
+}
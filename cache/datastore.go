@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"context"
+	"github.com/semirm-dev/findhotel/geo"
+)
+
+// Existing will find which of given ips are already stored, e.g. in data store
+type Existing interface {
+	Existing(ctx context.Context, ips []string) ([]string, error)
+}
+
+type datastore struct {
+	store Existing
+}
+
+// NewDatastore will initialize Cache which asks data store which ips already exist, it keeps nothing itself.
+// Duplicates of not yet stored ips are left to data store (geo.ConflictPolicy).
+func NewDatastore(store Existing) *datastore {
+	return &datastore{
+		store: store,
+	}
+}
+
+// Store is no-op, data store keeps *geo data itself
+func (c *datastore) Store(ctx context.Context, _ geo.CacheBucket) error {
+	return ctx.Err()
+}
+
+func (c *datastore) Get(ctx context.Context, keys []string) (geo.KeySet, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	existing := make(geo.KeySet)
+	if len(keys) == 0 {
+		return existing, nil
+	}
+
+	stored, err := c.store.Existing(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range stored {
+		existing[k] = struct{}{}
+	}
+
+	return existing, nil
+}
+
+// Purge is no-op, there are no keys to remove
+func (c *datastore) Purge() error {
+	return nil
+}
@@ -1,34 +1,101 @@
 package cache
 
-import "github.com/semirm-dev/findhotel/geo"
+import (
+	"context"
+	"github.com/semirm-dev/findhotel/geo"
+	"strings"
+	"sync"
+	"time"
+)
+
+type item struct {
+	value string
+	// expiresAt is zero for items without ttl
+	expiresAt time.Time
+}
 
 type inmemory struct {
-	items map[string]string
+	mu    sync.RWMutex
+	items map[string]*item
+	// Prefix (optional) is namespace of stored keys, e.g. DedupPrefix
+	Prefix string
+	// Ttl (optional) is how long stored keys are kept, 0 keeps them forever
+	Ttl time.Duration
 }
 
 func NewInMemory() *inmemory {
 	return &inmemory{
-		items: make(map[string]string),
+		items: make(map[string]*item),
 	}
 }
 
-func (c *inmemory) Store(items geo.CacheBucket) error {
+func (c *inmemory) Store(ctx context.Context, items geo.CacheBucket) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if c.Ttl > 0 {
+		expiresAt = time.Now().Add(c.Ttl)
+	}
+
 	for k, v := range items {
-		c.items[k] = v
+		c.items[c.Prefix+k] = &item{value: v, expiresAt: expiresAt}
 	}
 
 	return nil
 }
 
-func (c *inmemory) Get(keys []string) ([]string, error) {
-	values := make([]string, 0)
+func (c *inmemory) Get(ctx context.Context, keys []string) (geo.KeySet, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	existing := make(geo.KeySet)
 	for _, k := range keys {
-		values = append(values, c.items[k])
+		if i, ok := c.items[c.Prefix+k]; ok && !i.expired() {
+			existing[k] = struct{}{}
+		}
 	}
 
-	return values, nil
+	return existing, nil
 }
 
+// Purge will remove all keys in Prefix namespace
+func (c *inmemory) Purge() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k := range c.items {
+		if strings.HasPrefix(k, c.Prefix) {
+			delete(c.items, k)
+		}
+	}
+
+	return nil
+}
+
+// All returns all not expired items, from every namespace
 func (c *inmemory) All() map[string]string {
-	return c.items
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	all := make(map[string]string)
+	for k, i := range c.items {
+		if !i.expired() {
+			all[k] = i.value
+		}
+	}
+
+	return all
+}
+
+func (i *item) expired() bool {
+	return !i.expiresAt.IsZero() && time.Now().After(i.expiresAt)
 }
@@ -0,0 +1,43 @@
+package cache_test
+
+import (
+	"context"
+	"github.com/semirm-dev/findhotel/cache"
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestInMemory_Namespace(t *testing.T) {
+	c := cache.NewInMemory()
+
+	c.Prefix = cache.DedupPrefix("hotels", "")
+	assert.Nil(t, c.Store(context.Background(), geo.CacheBucket{"1.1.1.1": "1.1.1.1"}))
+
+	c.Prefix = cache.DedupPrefix("flights", "run1")
+	assert.Nil(t, c.Store(context.Background(), geo.CacheBucket{"2.2.2.2": "2.2.2.2"}))
+
+	existing, err := c.Get(context.Background(), []string{"1.1.1.1", "2.2.2.2"})
+	assert.Nil(t, err)
+	assert.Equal(t, geo.KeySet{"2.2.2.2": {}}, existing)
+
+	assert.Nil(t, c.Purge())
+	assert.Equal(t, map[string]string{"geo:dedup:hotels:1.1.1.1": "1.1.1.1"}, c.All())
+}
+
+func TestInMemory_Ttl(t *testing.T) {
+	c := cache.NewInMemory()
+	c.Ttl = 10 * time.Millisecond
+
+	assert.Nil(t, c.Store(context.Background(), geo.CacheBucket{"1.1.1.1": "1.1.1.1"}))
+	existing, err := c.Get(context.Background(), []string{"1.1.1.1"})
+	assert.Nil(t, err)
+	assert.True(t, existing.Has("1.1.1.1"))
+
+	time.Sleep(20 * time.Millisecond)
+
+	existing, err = c.Get(context.Background(), []string{"1.1.1.1"})
+	assert.Nil(t, err)
+	assert.False(t, existing.Has("1.1.1.1"))
+}
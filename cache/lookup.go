@@ -0,0 +1,215 @@
+package cache
+
+import (
+	"context"
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/sirupsen/logrus"
+	"sync/atomic"
+	"time"
+)
+
+// runsPage is number of import runs searched at once for the latest finished one
+const runsPage = 20
+
+// LookupBackend keeps looked up *geo data, nil *geo data is cached not found result
+type LookupBackend interface {
+	// Get returns cached results keyed by ip, ips which are not cached are not in the map
+	Get(ctx context.Context, ips []string) (map[string]*geo.Geo, error)
+	// Set will cache results for ttl
+	Set(ctx context.Context, results map[string]*geo.Geo, ttl time.Duration) error
+	// Invalidate will drop all cached results
+	Invalidate() error
+}
+
+// LookupStats are counters of cached and not cached ip lookups
+type LookupStats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+}
+
+type lookup struct {
+	search  geo.Search
+	backend LookupBackend
+	// Ttl is how long found *geo data is cached, 0 disables it
+	Ttl time.Duration
+	// NegativeTtl is how long not found result is cached, 0 disables it
+	NegativeTtl time.Duration
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+}
+
+// NewLookup will initialize read-through cache in front of geo.Search.
+// Backend failures are logged and lookups fall back to geo.Search.
+func NewLookup(search geo.Search, backend LookupBackend) *lookup {
+	return &lookup{
+		search:      search,
+		backend:     backend,
+		Ttl:         10 * time.Minute,
+		NegativeTtl: time.Minute,
+	}
+}
+
+func (c *lookup) ByIp(ctx context.Context, ip string) (*geo.Geo, error) {
+	found, err := c.ByIps(ctx, []string{ip})
+	if err != nil {
+		return nil, err
+	}
+
+	return found[ip], nil
+}
+
+func (c *lookup) ByIps(ctx context.Context, ips []string) (map[string]*geo.Geo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// ips are cached by their normalized form, so different forms of the same ip share cached result
+	keyOf := make(map[string]string, len(ips))
+	keys := make([]string, 0, len(ips))
+	seen := make(map[string]struct{}, len(ips))
+	for _, ip := range ips {
+		key, ok := geo.NormalizeLookupIp(ip)
+		if !ok {
+			key = ip
+		}
+		keyOf[ip] = key
+
+		if _, ok = seen[key]; !ok {
+			seen[key] = struct{}{}
+			keys = append(keys, key)
+		}
+	}
+
+	cached, err := c.backend.Get(ctx, keys)
+	if err != nil {
+		logrus.Errorf("failed to get %d ips from lookup cache: %v", len(keys), err)
+		cached = nil
+	}
+
+	results := make(map[string]*geo.Geo)
+	misses := make([]string, 0)
+	for _, key := range keys {
+		g, ok := cached[key]
+		if !ok {
+			misses = append(misses, key)
+			continue
+		}
+		results[key] = g
+	}
+
+	if len(misses) > 0 {
+		searched, err := c.search.ByIps(ctx, misses)
+		if err != nil {
+			return nil, err
+		}
+
+		positive := make(map[string]*geo.Geo)
+		negative := make(map[string]*geo.Geo)
+		for _, key := range misses {
+			g, ok := searched[key]
+			if !ok {
+				negative[key] = nil
+				continue
+			}
+			results[key] = g
+			positive[key] = g
+		}
+
+		c.set(ctx, positive, c.Ttl)
+		c.set(ctx, negative, c.NegativeTtl)
+	}
+
+	found := make(map[string]*geo.Geo)
+	hits := 0
+	for _, ip := range ips {
+		key := keyOf[ip]
+		if _, ok := cached[key]; ok {
+			hits++
+		}
+		if g := results[key]; g != nil {
+			found[ip] = g
+		}
+	}
+	c.hits.Add(uint64(hits))
+	c.misses.Add(uint64(len(ips) - hits))
+
+	return found, nil
+}
+
+func (c *lookup) set(ctx context.Context, results map[string]*geo.Geo, ttl time.Duration) {
+	if len(results) == 0 || ttl <= 0 {
+		return
+	}
+
+	if err := c.backend.Set(ctx, results, ttl); err != nil {
+		logrus.Errorf("failed to set %d ips in lookup cache: %v", len(results), err)
+	}
+}
+
+// Invalidate will drop all cached results, e.g. after *geo data is re-imported
+func (c *lookup) Invalidate() error {
+	return c.backend.Invalidate()
+}
+
+// Stats returns number of lookups served from cache (hits) and from geo.Search (misses)
+func (c *lookup) Stats() *LookupStats {
+	return &LookupStats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+	}
+}
+
+// WatchImports will invalidate cache each time a new import run is finished.
+// It checks import history every interval until ctx is done.
+func (c *lookup) WatchImports(ctx context.Context, runs geo.RunSearch, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last, err := lastFinishedRun(ctx, runs)
+	if err != nil {
+		logrus.Error("failed to get import runs: ", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			run, err := lastFinishedRun(ctx, runs)
+			if err != nil {
+				logrus.Error("failed to get import runs: ", err)
+				continue
+			}
+			if run == "" || run == last {
+				continue
+			}
+
+			if err = c.Invalidate(); err != nil {
+				logrus.Error("failed to invalidate lookup cache: ", err)
+				continue
+			}
+			last = run
+			logrus.Infof("lookup cache invalidated after import run %s", run)
+		}
+	}
+}
+
+// lastFinishedRun returns id of the latest finished import run, empty if there is none
+func lastFinishedRun(ctx context.Context, runs geo.RunSearch) (string, error) {
+	for offset := 0; ; offset += runsPage {
+		page, err := runs.Runs(ctx, offset, runsPage)
+		if err != nil {
+			return "", err
+		}
+
+		for _, r := range page {
+			if r.FinishedAt != nil {
+				return r.Id, nil
+			}
+		}
+
+		if len(page) < runsPage {
+			return "", nil
+		}
+	}
+}
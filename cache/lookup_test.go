@@ -0,0 +1,191 @@
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/semirm-dev/findhotel/cache"
+	"github.com/semirm-dev/findhotel/datastore"
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// countingSearch counts ips which reached geo.Search
+type countingSearch struct {
+	geo.Search
+	searched int
+}
+
+func (s *countingSearch) ByIps(ctx context.Context, ips []string) (map[string]*geo.Geo, error) {
+	s.searched += len(ips)
+	return s.Search.ByIps(ctx, ips)
+}
+
+func newCountingSearch(t *testing.T) *countingSearch {
+	store := datastore.NewInMemory()
+	_, err := store.Store(context.Background(), []*geo.Geo{
+		{Ip: "1.1.1.1", CountryCode: "cc1"},
+		{Ip: "2.2.2.2", CountryCode: "cc2"},
+	}, geo.ConflictSkip)
+	assert.Nil(t, err)
+
+	return &countingSearch{Search: store}
+}
+
+func TestLookup_ByIp(t *testing.T) {
+	search := newCountingSearch(t)
+	lookup := cache.NewLookup(search, cache.NewLru(10))
+
+	for i := 0; i < 3; i++ {
+		g, err := lookup.ByIp(context.Background(), "1.1.1.1")
+		assert.Nil(t, err)
+		assert.Equal(t, "cc1", g.CountryCode)
+
+		g, err = lookup.ByIp(context.Background(), "5.5.5.5")
+		assert.Nil(t, err)
+		assert.Nil(t, g)
+	}
+
+	assert.Equal(t, 2, search.searched)
+	assert.Equal(t, &cache.LookupStats{Hits: 4, Misses: 2}, lookup.Stats())
+}
+
+func TestLookup_ByIps(t *testing.T) {
+	search := newCountingSearch(t)
+	lookup := cache.NewLookup(search, cache.NewLru(10))
+
+	_, err := lookup.ByIp(context.Background(), "1.1.1.1")
+	assert.Nil(t, err)
+
+	found, err := lookup.ByIps(context.Background(), []string{"1.1.1.1", "2.2.2.2", "5.5.5.5"})
+	assert.Nil(t, err)
+	assert.Len(t, found, 2)
+	assert.Equal(t, "cc1", found["1.1.1.1"].CountryCode)
+	assert.Equal(t, "cc2", found["2.2.2.2"].CountryCode)
+
+	assert.Equal(t, 3, search.searched)
+	assert.Equal(t, &cache.LookupStats{Hits: 1, Misses: 3}, lookup.Stats())
+}
+
+func TestLookup_NormalizedIps(t *testing.T) {
+	search := newCountingSearch(t)
+	_, err := search.Search.(geo.Storer).Store(context.Background(), []*geo.Geo{{Ip: "2001:db8::1", CountryCode: "cc3"}}, geo.ConflictSkip)
+	assert.Nil(t, err)
+
+	backend := cache.NewLru(10)
+	lookup := cache.NewLookup(search, backend)
+
+	found, err := lookup.ByIps(context.Background(), []string{"2001:0DB8::1", "2001:db8::1"})
+	assert.Nil(t, err)
+	assert.Len(t, found, 2)
+	assert.Equal(t, "cc3", found["2001:0DB8::1"].CountryCode)
+	assert.Equal(t, "cc3", found["2001:db8::1"].CountryCode)
+
+	g, err := lookup.ByIp(context.Background(), "2001:DB8:0::1")
+	assert.Nil(t, err)
+	assert.Equal(t, "cc3", g.CountryCode)
+
+	// every form is searched and cached only once, by normalized ip
+	assert.Equal(t, 1, search.searched)
+	cached, err := backend.Get(context.Background(), []string{"2001:db8::1"})
+	assert.Nil(t, err)
+	assert.Len(t, cached, 1)
+}
+
+func TestLookup_Ttl(t *testing.T) {
+	search := newCountingSearch(t)
+	lookup := cache.NewLookup(search, cache.NewLru(10))
+	lookup.Ttl = time.Hour
+	lookup.NegativeTtl = 10 * time.Millisecond
+
+	_, _ = lookup.ByIps(context.Background(), []string{"1.1.1.1", "5.5.5.5"})
+	time.Sleep(20 * time.Millisecond)
+	_, _ = lookup.ByIps(context.Background(), []string{"1.1.1.1", "5.5.5.5"})
+
+	// only not found ip expired
+	assert.Equal(t, 3, search.searched)
+}
+
+func TestLookup_Invalidate(t *testing.T) {
+	search := newCountingSearch(t)
+	lookup := cache.NewLookup(search, cache.NewLru(10))
+
+	_, _ = lookup.ByIp(context.Background(), "1.1.1.1")
+	assert.Nil(t, lookup.Invalidate())
+	_, _ = lookup.ByIp(context.Background(), "1.1.1.1")
+
+	assert.Equal(t, 2, search.searched)
+}
+
+func TestLookup_WatchImports(t *testing.T) {
+	search := newCountingSearch(t)
+	lookup := cache.NewLookup(search, cache.NewLru(10))
+	history := datastore.NewInMemoryHistory()
+
+	finishedAt := time.Now()
+	assert.Nil(t, history.SaveRun(context.Background(), &geo.Run{Id: "run1", StartedAt: finishedAt, FinishedAt: &finishedAt, Status: geo.RunCompleted}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go lookup.WatchImports(ctx, history, 5*time.Millisecond)
+
+	// run finished before watching started is not invalidating
+	_, _ = lookup.ByIp(context.Background(), "1.1.1.1")
+	time.Sleep(20 * time.Millisecond)
+	_, _ = lookup.ByIp(context.Background(), "1.1.1.1")
+	assert.Equal(t, 1, search.searched)
+
+	finishedAt = time.Now()
+	assert.Nil(t, history.SaveRun(context.Background(), &geo.Run{Id: "run2", StartedAt: finishedAt, FinishedAt: &finishedAt, Status: geo.RunCompleted}))
+
+	assert.Eventually(t, func() bool {
+		_, _ = lookup.ByIp(context.Background(), "1.1.1.1")
+		return search.searched > 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestLru_Evicts(t *testing.T) {
+	lru := cache.NewLru(2)
+
+	assert.Nil(t, lru.Set(context.Background(), map[string]*geo.Geo{"1.1.1.1": {Ip: "1.1.1.1"}, "2.2.2.2": nil}, time.Hour))
+	_, _ = lru.Get(context.Background(), []string{"1.1.1.1"})
+	assert.Nil(t, lru.Set(context.Background(), map[string]*geo.Geo{"3.3.3.3": {Ip: "3.3.3.3"}}, time.Hour))
+
+	cached, err := lru.Get(context.Background(), []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"})
+	assert.Nil(t, err)
+	assert.Len(t, cached, 2)
+	assert.Contains(t, cached, "1.1.1.1")
+	assert.Contains(t, cached, "3.3.3.3")
+}
+
+func TestRedisLookup_Invalidate(t *testing.T) {
+	conf := cache.NewRedisConfig()
+	conf.Host, _ = redisNamespace(t)
+	dataset := fmt.Sprintf("test-%d", time.Now().UnixNano())
+
+	own := cache.NewRedisLookup(conf, dataset)
+	if err := own.Initialize(); err != nil {
+		t.Skipf("redis is not available: %v", err)
+	}
+	other := cache.NewRedisLookup(conf, dataset+"-other")
+	assert.Nil(t, other.Initialize())
+	t.Cleanup(func() {
+		assert.Nil(t, own.Invalidate())
+		assert.Nil(t, other.Invalidate())
+	})
+
+	results := map[string]*geo.Geo{"1.1.1.1": {Ip: "1.1.1.1"}}
+	assert.Nil(t, own.Set(context.Background(), results, time.Minute))
+	assert.Nil(t, other.Set(context.Background(), results, time.Minute))
+
+	assert.Nil(t, own.Invalidate())
+
+	cached, err := own.Get(context.Background(), []string{"1.1.1.1"})
+	assert.Nil(t, err)
+	assert.Empty(t, cached)
+
+	cached, err = other.Get(context.Background(), []string{"1.1.1.1"})
+	assert.Nil(t, err)
+	assert.Len(t, cached, 1)
+}
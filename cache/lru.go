@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"github.com/semirm-dev/findhotel/geo"
+	"sync"
+	"time"
+)
+
+type lruEntry struct {
+	ip        string
+	geoData   *geo.Geo
+	expiresAt time.Time
+}
+
+type lru struct {
+	mu       sync.Mutex
+	capacity int
+	// entries are ordered from the most recently used one
+	entries *list.List
+	items   map[string]*list.Element
+}
+
+// NewLru will initialize in-memory LookupBackend, the least recently used results are evicted above capacity
+func NewLru(capacity int) *lru {
+	return &lru{
+		capacity: capacity,
+		entries:  list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (c *lru) Get(_ context.Context, ips []string) (map[string]*geo.Geo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	cached := make(map[string]*geo.Geo)
+	for _, ip := range ips {
+		el, ok := c.items[ip]
+		if !ok {
+			continue
+		}
+
+		entry := el.Value.(*lruEntry)
+		if now.After(entry.expiresAt) {
+			c.remove(el)
+			continue
+		}
+
+		c.entries.MoveToFront(el)
+		cached[ip] = entry.geoData
+	}
+
+	return cached, nil
+}
+
+func (c *lru) Set(_ context.Context, results map[string]*geo.Geo, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+	for ip, g := range results {
+		if el, ok := c.items[ip]; ok {
+			el.Value = &lruEntry{ip: ip, geoData: g, expiresAt: expiresAt}
+			c.entries.MoveToFront(el)
+			continue
+		}
+
+		c.items[ip] = c.entries.PushFront(&lruEntry{ip: ip, geoData: g, expiresAt: expiresAt})
+		if c.entries.Len() > c.capacity {
+			c.remove(c.entries.Back())
+		}
+	}
+
+	return nil
+}
+
+func (c *lru) Invalidate() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries.Init()
+	c.items = make(map[string]*list.Element)
+
+	return nil
+}
+
+func (c *lru) remove(el *list.Element) {
+	c.entries.Remove(el)
+	delete(c.items, el.Value.(*lruEntry).ip)
+}
@@ -1,13 +1,23 @@
 package cache
 
 import (
+	"context"
+	"errors"
 	redisLib "github.com/go-redis/redis"
 	"github.com/semirm-dev/findhotel/geo"
+	"strings"
+	"time"
 )
 
 // pipeLength defines limit whether to use pipeline or not
 const pipeLength = 1
 
+// dedupPrefix is namespace of all keys stored by dedup cache, it keeps them apart from lookup cache and other keys
+const dedupPrefix = "geo:dedup:"
+
+// scanCount is number of keys scanned and deleted at once
+const scanCount = 1000
+
 type redis struct {
 	*redisLib.Client
 	*redisConfig
@@ -19,6 +29,10 @@ type redisConfig struct {
 	Password   string
 	DB         int
 	PipeLength int
+	// Prefix is namespace of stored keys, see DedupPrefix
+	Prefix string
+	// Ttl (optional) is how long stored keys are kept, 0 keeps them forever
+	Ttl time.Duration
 }
 
 func NewRedisConfig() *redisConfig {
@@ -27,9 +41,20 @@ func NewRedisConfig() *redisConfig {
 		Port:     "6379",
 		Password: "",
 		DB:       0,
+		Prefix:   DedupPrefix("default", ""),
 	}
 }
 
+// DedupPrefix returns namespace for dataset keys, run (optional) scopes them to a single import run
+func DedupPrefix(dataset, run string) string {
+	prefix := dedupPrefix + dataset + ":"
+	if run != "" {
+		prefix += run + ":"
+	}
+
+	return prefix
+}
+
 func NewRedis(conf *redisConfig) *redis {
 	return &redis{
 		redisConfig: conf,
@@ -57,33 +82,120 @@ func (c *redis) Initialize() error {
 	return nil
 }
 
-func (c *redis) Store(items geo.CacheBucket) error {
-	pipe := c.Pipeline()
+func (c *redis) Store(ctx context.Context, items geo.CacheBucket) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if len(items) == 0 {
+		return nil
+	}
+
+	pipe := c.WithContext(ctx).Pipeline()
 
 	for k, v := range items {
-		pipe.Set(k, v, -1)
+		pipe.Set(c.redisConfig.Prefix+k, v, c.redisConfig.Ttl)
 	}
 
 	_, err := pipe.Exec()
 	return err
 }
 
-func (c *redis) Get(keys []string) ([]string, error) {
-	pipe := c.Pipeline()
+func (c *redis) Get(ctx context.Context, keys []string) (geo.KeySet, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	existing := make(geo.KeySet)
+	if len(keys) == 0 {
+		return existing, nil
+	}
+
+	pipe := c.WithContext(ctx).Pipeline()
 
+	cmds := make([]*redisLib.IntCmd, 0, len(keys))
 	for _, k := range keys {
-		pipe.Get(k)
+		cmds = append(cmds, pipe.Exists(c.redisConfig.Prefix+k))
 	}
 
-	res, err := pipe.Exec()
-	if err != nil && err != redisLib.Nil {
+	if _, err := pipe.Exec(); err != nil {
 		return nil, err
 	}
 
-	values := make([]string, 0)
-	for _, item := range res {
-		values = append(values, item.(*redisLib.StringCmd).Val())
+	for i, cmd := range cmds {
+		if cmd.Val() > 0 {
+			existing[keys[i]] = struct{}{}
+		}
+	}
+
+	return existing, nil
+}
+
+// Purge will remove all keys in Prefix namespace
+func (c *redis) Purge() error {
+	if c.redisConfig.Prefix == "" {
+		return errors.New("refusing to purge redis without key prefix")
+	}
+
+	return c.deleteMatching(escapePattern(c.redisConfig.Prefix) + "*")
+}
+
+// PurgeLegacy will remove dedup keys stored before they were namespaced, these are bare ips and networks
+// (e.g. "1.1.1.1") which Purge can't reach. Other keys are kept, number of removed keys is returned.
+func (c *redis) PurgeLegacy() (int, error) {
+	removed := 0
+
+	var cursor uint64
+	for {
+		keys, next, err := c.Scan(cursor, "*", scanCount).Result()
+		if err != nil {
+			return removed, err
+		}
+
+		var legacy []string
+		for _, k := range keys {
+			if _, err = geo.NormalizeIp(k); err == nil {
+				legacy = append(legacy, k)
+			}
+		}
+
+		if len(legacy) > 0 {
+			if err = c.Del(legacy...).Err(); err != nil {
+				return removed, err
+			}
+			removed += len(legacy)
+		}
+
+		if next == 0 {
+			return removed, nil
+		}
+		cursor = next
 	}
+}
+
+// deleteMatching will scan and delete all keys matching pattern
+func (c *redis) deleteMatching(pattern string) error {
+	var cursor uint64
+	for {
+		keys, next, err := c.Scan(cursor, pattern, scanCount).Result()
+		if err != nil {
+			return err
+		}
+
+		if len(keys) > 0 {
+			if err = c.Del(keys...).Err(); err != nil {
+				return err
+			}
+		}
+
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
 
-	return values, nil
+// escapePattern will escape glob characters, so that s is matched literally by SCAN
+func escapePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`).Replace(s)
 }
@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	redisLib "github.com/go-redis/redis"
+	"github.com/semirm-dev/findhotel/geo"
+	"math"
+)
+
+// maxRedisBits is max size of redis bitmap (512MB)
+const maxRedisBits = math.MaxUint32
+
+type redisBloom struct {
+	*redis
+	m uint64
+	k uint64
+	// Confirm (optional) is asked if probable duplicates are really stored, to rule out false positives
+	Confirm geo.Search
+}
+
+// NewRedisBloom will initialize bloom filter Cache kept in redis bitmap under Prefix namespace, shared by all loaders.
+// Unlike in-process bloom filter it doesn't grow, error rate is kept only up to capacity keys.
+func NewRedisBloom(conf *redisConfig, capacity int, errorRate float64) (*redisBloom, error) {
+	if err := validateBloom(capacity, errorRate); err != nil {
+		return nil, err
+	}
+
+	m, k := optimal(uint64(capacity), errorRate)
+	if m > maxRedisBits {
+		return nil, fmt.Errorf("bloom filter of %d bits doesn't fit in redis bitmap, lower capacity or raise error rate", m)
+	}
+
+	return &redisBloom{
+		redis: NewRedis(conf),
+		m:     m,
+		k:     k,
+	}, nil
+}
+
+func (c *redisBloom) Store(ctx context.Context, items geo.CacheBucket) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if len(items) == 0 {
+		return nil
+	}
+
+	pipe := c.WithContext(ctx).Pipeline()
+
+	for k := range items {
+		for _, p := range positions(k, c.m, c.k) {
+			pipe.SetBit(c.key(), int64(p), 1)
+		}
+	}
+
+	_, err := pipe.Exec()
+	return err
+}
+
+func (c *redisBloom) Get(ctx context.Context, keys []string) (geo.KeySet, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return make(geo.KeySet), nil
+	}
+
+	pipe := c.WithContext(ctx).Pipeline()
+
+	bits := make([][]*redisLib.IntCmd, 0, len(keys))
+	for _, k := range keys {
+		cmds := make([]*redisLib.IntCmd, 0, c.k)
+		for _, p := range positions(k, c.m, c.k) {
+			cmds = append(cmds, pipe.GetBit(c.key(), int64(p)))
+		}
+		bits = append(bits, cmds)
+	}
+
+	if _, err := pipe.Exec(); err != nil {
+		return nil, err
+	}
+
+	probable := make([]string, 0)
+	for i, cmds := range bits {
+		if allSet(cmds) {
+			probable = append(probable, keys[i])
+		}
+	}
+
+	return confirm(ctx, c.Confirm, probable)
+}
+
+// Purge will remove bloom filter bitmap
+func (c *redisBloom) Purge() error {
+	return c.Del(c.key()).Err()
+}
+
+func (c *redisBloom) key() string {
+	return c.redisConfig.Prefix + "bloom"
+}
+
+func allSet(cmds []*redisLib.IntCmd) bool {
+	for _, cmd := range cmds {
+		if cmd.Val() == 0 {
+			return false
+		}
+	}
+
+	return true
+}
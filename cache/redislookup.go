@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/semirm-dev/findhotel/geo"
+	"time"
+)
+
+// lookupPrefix is namespace of all cached lookup results, it keeps them apart from dedup cache and other keys
+const lookupPrefix = "geo:lookup:"
+
+type redisLookup struct {
+	*redis
+	prefix string
+}
+
+// NewRedisLookup will initialize LookupBackend which keeps results in redis, shared by all gateways.
+// Results are namespaced by dataset (see LookupPrefix), so gateways of different datasets don't share them.
+func NewRedisLookup(conf *redisConfig, dataset string) *redisLookup {
+	return &redisLookup{
+		redis:  NewRedis(conf),
+		prefix: LookupPrefix(dataset),
+	}
+}
+
+// LookupPrefix returns namespace for cached lookup results of dataset
+func LookupPrefix(dataset string) string {
+	return lookupPrefix + dataset + ":"
+}
+
+func (c *redisLookup) Get(ctx context.Context, ips []string) (map[string]*geo.Geo, error) {
+	cached := make(map[string]*geo.Geo)
+	if len(ips) == 0 {
+		return cached, nil
+	}
+
+	keys := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		keys = append(keys, c.prefix+ip)
+	}
+
+	values, err := c.WithContext(ctx).MGet(keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+
+		var g *geo.Geo
+		if err = json.Unmarshal([]byte(s), &g); err != nil {
+			return nil, err
+		}
+		cached[ips[i]] = g
+	}
+
+	return cached, nil
+}
+
+func (c *redisLookup) Set(ctx context.Context, results map[string]*geo.Geo, ttl time.Duration) error {
+	pipe := c.WithContext(ctx).Pipeline()
+
+	for ip, g := range results {
+		v, err := json.Marshal(g)
+		if err != nil {
+			return err
+		}
+		pipe.Set(c.prefix+ip, v, ttl)
+	}
+
+	_, err := pipe.Exec()
+	return err
+}
+
+func (c *redisLookup) Invalidate() error {
+	return c.deleteMatching(escapePattern(c.prefix) + "*")
+}
@@ -0,0 +1,106 @@
+package checkpoint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/semirm-dev/findhotel/geo"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Checkpoint is the last committed position in source file
+type Checkpoint struct {
+	Path      string    `json:"path"`
+	Hash      string    `json:"hash"`
+	Offset    int64     `json:"offset"`
+	Line      int       `json:"line"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type file struct {
+	statePath string
+	path      string
+	hash      string
+}
+
+// NewFile will initialize geo.Checkpointer which persists checkpoints of source at path into json file at statePath.
+// Hash is source file content hash (see Hash), so checkpoint of a different file content is never resumed.
+func NewFile(statePath, path, hash string) *file {
+	return &file{
+		statePath: statePath,
+		path:      path,
+		hash:      hash,
+	}
+}
+
+// Commit will atomically replace checkpoint file with given row position
+func (f *file) Commit(row *geo.Row) error {
+	data, err := json.Marshal(&Checkpoint{
+		Path:      f.path,
+		Hash:      f.hash,
+		Offset:    row.Offset,
+		Line:      row.Line,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.statePath), filepath.Base(f.statePath)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.statePath)
+}
+
+// Last returns last committed checkpoint, or nil if there is none for the same source file content
+func (f *file) Last() (*Checkpoint, error) {
+	data, err := os.ReadFile(f.statePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var cp *Checkpoint
+	if err = json.Unmarshal(data, &cp); err != nil {
+		return nil, err
+	}
+
+	if cp.Hash != f.hash {
+		return nil, nil
+	}
+
+	return cp, nil
+}
+
+// Hash returns hex encoded sha256 of file content
+func Hash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -1,26 +1,105 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"github.com/gin-gonic/gin"
+	"github.com/semirm-dev/findhotel/cache"
 	"github.com/semirm-dev/findhotel/datastore"
 	"github.com/semirm-dev/findhotel/gateway"
+	"github.com/semirm-dev/findhotel/geo"
 	"github.com/semirm-dev/findhotel/internal/db"
+	"github.com/semirm-dev/findhotel/internal/grpc"
 	"github.com/semirm-dev/findhotel/internal/web"
+	"github.com/semirm-dev/findhotel/metrics"
+	"github.com/semirm-dev/findhotel/tracing"
+	"github.com/sirupsen/logrus"
+	"time"
 )
 
 const defaultConnStr = "host=localhost port=5432 dbname=findhotel_geo user=postgres password=postgres sslmode=disable"
 
 var (
-	httpAddr   = flag.String("http", ":8000", "Http address")
-	connString = flag.String("c", defaultConnStr, "Database connection string")
+	httpAddr          = flag.String("http", ":8000", "Http address")
+	grpcAddr          = flag.String("grpc", ":8001", "Grpc address of GeoService, empty to disable it")
+	connString        = flag.String("c", defaultConnStr, "Database connection string")
+	redisHost         = flag.String("r", "localhost", "Redis host, used by redis lookup cache")
+	lookupCache       = flag.String("lookup-cache", "none", "Cache for looked up ips: none, memory (LRU) or redis")
+	lookupSize        = flag.Int("lookup-size", 100000, "Max number of ips in memory lookup cache")
+	lookupTtl         = flag.Duration("lookup-ttl", 10*time.Minute, "How long found ips are cached")
+	lookupNegativeTtl = flag.Duration("lookup-negative-ttl", time.Minute, "How long not found ips are cached")
+	lookupWatch       = flag.Duration("lookup-watch", 30*time.Second, "How often import history is checked to invalidate lookup cache")
+	lookupDataset     = flag.String("lookup-dataset", "default", "Dataset name, redis lookup cache keys are namespaced by it")
+	traceExporter     = flag.String("trace-exporter", "none", "OpenTelemetry span exporter: none, stdout or otlp (http)")
+	traceEndpoint     = flag.String("trace-endpoint", "", "Otlp collector host:port, empty uses OTEL_EXPORTER_OTLP_* environment variables")
 )
 
 func main() {
 	flag.Parse()
 
+	shutdownTracing, err := tracing.Init(context.Background(), "findhotel-gateway", *traceExporter, *traceEndpoint)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logrus.Error("failed to flush spans: ", err)
+		}
+	}()
+
+	pg := db.PostgresDb(*connString)
+	history, err := datastore.NewPgHistory(pg)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
 	router := web.NewRouter()
+	router.Use(tracing.Gin(), metrics.Gin())
+	router.NoRoute(gateway.NotFound())
+
+	search, lookupStats := withLookupCache(tracing.NewSearch("datastore", metrics.NewSearch(datastore.NewPg(pg))), history)
+	metrics.RegisterLookupStats(lookupStats)
 
-	router.GET("geo", gateway.GetGeoLocation(datastore.NewPg(db.PostgresDb(*connString))))
+	router.GET("geo", gateway.GetGeoLocation(search))
+	router.POST("geo/batch", gateway.GetGeoLocations(search))
+	router.GET("imports", gateway.GetImports(history))
+	router.GET("imports/:id", gateway.GetImport(history))
+	router.GET("metrics", gin.WrapH(metrics.Handler()))
+
+	if *grpcAddr != "" {
+		go grpc.ListenForConnections(context.Background(), gateway.NewGeoService(search), *grpcAddr, "geo grpc service",
+			tracing.GrpcServerOptions()...)
+	}
 
 	web.ServeHttp(*httpAddr, "gateway", router)
 }
+
+// withLookupCache will put read-through cache in front of search, it's invalidated after each finished import run.
+// Returned stats are nil without lookup cache.
+func withLookupCache(search geo.Search, history geo.RunSearch) (geo.Search, metrics.LookupStats) {
+	var backend cache.LookupBackend
+	switch *lookupCache {
+	case "none":
+		return search, nil
+	case "memory":
+		backend = cache.NewLru(*lookupSize)
+	case "redis":
+		conf := cache.NewRedisConfig()
+		conf.Host = *redisHost
+		redisBackend := cache.NewRedisLookup(conf, *lookupDataset)
+		if err := redisBackend.Initialize(); err != nil {
+			logrus.Fatal(err)
+		}
+		backend = redisBackend
+	default:
+		logrus.Fatalf("unsupported lookup cache: %s", *lookupCache)
+	}
+
+	lookup := cache.NewLookup(search, tracing.NewLookupBackend(backend))
+	lookup.Ttl = *lookupTtl
+	lookup.NegativeTtl = *lookupNegativeTtl
+
+	go lookup.WatchImports(context.Background(), history, *lookupWatch)
+
+	return tracing.NewSearch("lookup", lookup), lookup
+}
@@ -2,38 +2,590 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
+	"fmt"
+	"github.com/gin-gonic/gin"
 	"github.com/semirm-dev/findhotel/cache"
+	"github.com/semirm-dev/findhotel/checkpoint"
 	"github.com/semirm-dev/findhotel/datastore"
+	"github.com/semirm-dev/findhotel/deadletter"
+	"github.com/semirm-dev/findhotel/gateway"
 	"github.com/semirm-dev/findhotel/geo"
 	"github.com/semirm-dev/findhotel/importer"
 	"github.com/semirm-dev/findhotel/internal/db"
+	"github.com/semirm-dev/findhotel/internal/grpc"
+	"github.com/semirm-dev/findhotel/internal/web"
+	"github.com/semirm-dev/findhotel/jobs"
+	"github.com/semirm-dev/findhotel/metrics"
+	"github.com/semirm-dev/findhotel/rejects"
+	"github.com/semirm-dev/findhotel/tracing"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
 const defaultConnStr = "host=localhost port=5432 dbname=findhotel_geo user=postgres password=postgres sslmode=disable"
 
 var (
-	csvPath    = flag.String("p", "cmd/loader/data_dump.csv", "path to csv file")
-	connString = flag.String("c", defaultConnStr, "Database connection string")
-	redisHost  = flag.String("r", "localhost", "Redis host")
-	batch      = flag.Int("b", 400, "Batch size")
-	workers    = flag.Int("w", 5, "Number of data store workers")
+	csvPath        = flag.String("p", "cmd/loader/data_dump.csv", "path to csv file")
+	storerType     = flag.String("storer", "insert", "Data store writer: insert (gorm bulk insert) or copy (postgres COPY)")
+	connString     = flag.String("c", defaultConnStr, "Database connection string")
+	redisHost      = flag.String("r", "localhost", "Redis host")
+	dataset        = flag.String("dataset", "default", "Dataset name, dedup cache keys are namespaced by it")
+	cacheRun       = flag.String("cache-run", "", "Scopes dedup cache keys to a single import run within dataset (optional)")
+	cacheTtl       = flag.Duration("cache-ttl", 0, "How long dedup cache keys are kept, 0 keeps them forever")
+	purgeCache     = flag.Bool("purge-cache", false, "Remove all dedup cache keys of -dataset (and -cache-run) and exit, requires -dedup=redis or -dedup=redis-bloom")
+	purgeLegacy    = flag.Bool("purge-legacy-cache", false, "Remove dedup cache keys stored in redis before they were namespaced by dataset (bare ips) and exit")
+	dedup          = flag.String("dedup", "redis", "Dedup cache: redis (key per ip), memory (in-process set), datastore (ask data store which ips exist), bloom (in-process bloom filter) or redis-bloom (bloom filter in redis bitmap)")
+	bloomCapacity  = flag.Int("bloom-capacity", 1000000, "Number of ips bloom filter is sized for, in-process filter grows beyond it")
+	bloomErrorRate = flag.Float64("bloom-error-rate", 0.001, "Bloom filter false positive rate (0-1)")
+	bloomSnapshot  = flag.String("bloom-snapshot", "", "path to in-process bloom filter snapshot, restored before and saved after import")
+	bloomConfirm   = flag.Bool("bloom-confirm", true, "Confirm probable duplicates against data store")
+	warm           = flag.String("warm", "none", "Rebuild dedup cache from data store before import: none, always or auto (only if sampled stored ips are missing in cache)")
+	warmBatch      = flag.Int("warm-batch", 10000, "Number of stored ips read at once while warming dedup cache")
+	warmSample     = flag.Int("warm-sample", 1000, "Number of stored ips checked in dedup cache with -warm=auto")
+	batch          = flag.Int("b", 400, "Batch size")
+	workers        = flag.Int("w", 5, "Number of data store workers")
+	chunks         = flag.Int("chunks", 1, "Number of csv file chunks parsed concurrently")
+	retries        = flag.Int("retries", 3, "Number of attempts to store each batch")
+	backoff        = flag.Duration("backoff", 100*time.Millisecond, "Initial delay between store attempts")
+	maxBackoff     = flag.Duration("max-backoff", 5*time.Second, "Max delay between store attempts")
+	jitter         = flag.Float64("jitter", 0.2, "Randomization of delay between store attempts (0-1)")
+	deadLetterPath = flag.String("dead-letter", "", "path to csv file for batches that failed to store, can be re-imported with -p")
+	inFileWindow   = flag.Int("in-file-window", 100000, "Number of the most recently accepted ips checked for duplicates within csv file, older ones are left to dedup cache and -conflict")
+	conflict       = flag.String("conflict", "skip", "What to do with already stored ips: skip, overwrite, overwrite-if-newer or fail")
+	rules          = flag.String("rules", "ip,coordinates,mystery_value", "Validation rules: ip, coordinates, country_code, country_consistency, mystery_value or all")
+	rejectsPath    = flag.String("rejects", "", "path to file for rejected rows, csv or jsonl (by extension)")
+	checkpointPath = flag.String("checkpoint", "", "path to checkpoint file, committed after each stored batch (default <csv path>.checkpoint with -resume)")
+	resume         = flag.Bool("resume", false, "Continue import from the last committed checkpoint")
+	staleRun       = flag.Duration("stale-run", 5*time.Minute, "Running import runs not saved for this long are marked interrupted on startup, running runs are saved every minute")
+	reportFormat   = flag.String("report", "text", "Import report format: text or json")
+	maxDiscardRate = flag.Float64("max-discard-rate", 1, "Exit with error if ratio of discarded to read rows exceeds it (0-1)")
+	serveAddr      = flag.String("serve", "", "Grpc address of ImportService, loader runs as a service instead of importing -p once (optional)")
+	dataDir        = flag.String("data-dir", ".", "Directory of csv files which can be imported in service mode")
+	serveHttpAddr  = flag.String("serve-http", ":8003", "Http address of import progress events in service mode, empty to disable it")
+	progressEvery  = flag.Duration("progress", time.Second, "How often import progress is published, 0 disables progress line")
+	metricsAddr    = flag.String("metrics-addr", "", "Http address of prometheus /metrics endpoint (optional), in service mode metrics are also on -serve-http")
+	metricsPush    = flag.String("metrics-push", "", "Prometheus pushgateway url, metrics are pushed once import is finished (optional)")
+	traceExporter  = flag.String("trace-exporter", "none", "OpenTelemetry span exporter: none, stdout or otlp (http)")
+	traceEndpoint  = flag.String("trace-endpoint", "", "Otlp collector host:port, empty uses OTEL_EXPORTER_OTLP_* environment variables")
 )
 
 func main() {
 	flag.Parse()
 
-	impCtx, impCancel := context.WithCancel(context.Background())
-	defer impCancel()
+	if *purgeLegacy {
+		purgeLegacyKeys()
+		return
+	}
+
+	if *purgeCache {
+		if *dedup != "redis" && *dedup != "redis-bloom" {
+			logrus.Fatalf("-purge-cache requires -dedup=redis or -dedup=redis-bloom, %s dedup cache is not kept in redis", *dedup)
+		}
+
+		cacheStore, closeCache, err := dedupCache(nil)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		if err = cacheStore.Purge(); err != nil {
+			logrus.Fatal(err)
+		}
+		closeCache()
+		logrus.Infof("dedup cache %s purged", cache.DedupPrefix(*dataset, *cacheRun))
+		return
+	}
+
+	shutdownTracing, err := tracing.Init(context.Background(), "findhotel-loader", *traceExporter, *traceEndpoint)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	stores, err := newPgStores(db.PostgresDb(*connString))
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	if *metricsAddr != "" {
+		go serveMetrics()
+	}
+
+	if *serveAddr != "" {
+		serve(stores)
+		flushSpans(shutdownTracing)
+		return
+	}
+
+	ldr, closeLoader, err := newLoader(context.Background(), stores, "", *csvPath)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	if *progressEvery > 0 {
+		ldr.Subscribe(printProgress)
+	}
+	report := ldr.Load(context.Background(), *workers)
+	closeLoader()
+	flushSpans(shutdownTracing)
+
+	if *metricsPush != "" {
+		if err = metrics.Push(*metricsPush, "findhotel_loader"); err != nil {
+			logrus.Error("failed to push metrics: ", err)
+		}
+	}
+
+	if err = printReport(report); err != nil {
+		logrus.Fatal(err)
+	}
+
+	if report.DiscardRate() > *maxDiscardRate {
+		logrus.Fatalf("discard rate %.4f exceeds max %.4f", report.DiscardRate(), *maxDiscardRate)
+	}
+}
+
+// serve will run loader as a long-running service, imports are started and monitored over grpc ImportService
+func serve(stores *pgStores) {
+	manager := jobs.NewManager(*dataDir, func(ctx context.Context, id, path string) (jobs.Loader, func(), error) {
+		return newLoader(ctx, stores, id, path)
+	})
+	manager.Workers = *workers
+	manager.History = stores.history
+
+	if *serveHttpAddr == "" {
+		grpc.ListenForConnections(context.Background(), jobs.NewImportService(manager), *serveAddr, "import grpc service",
+			tracing.GrpcServerOptions()...)
+		return
+	}
+
+	go grpc.ListenForConnections(context.Background(), jobs.NewImportService(manager), *serveAddr, "import grpc service",
+		tracing.GrpcServerOptions()...)
+
+	router := web.NewRouter()
+	router.Use(tracing.Gin())
+	router.NoRoute(gateway.NotFound())
+	router.GET("imports/:id/progress", gateway.GetImportProgress(manager))
+	router.GET("metrics", gin.WrapH(metrics.Handler()))
+
+	web.ServeHttp(*serveHttpAddr, "loader", router)
+}
+
+// geoStore is postgres data store of *geo data, used to store, dedup and warm up dedup cache
+type geoStore interface {
+	geo.Storer
+	geo.IpScanner
+	dedupStore
+}
 
-	ds := datastore.NewPg(db.PostgresDb(*connString))
+// pgStores are data stores shared by all imports, their tables are migrated once they are created
+type pgStores struct {
+	geo geoStore
+	// storer is selected with -storer
+	storer  geo.Storer
+	history runHistory
+}
+
+// runHistory saves import runs, and finds runs of evicted import jobs
+type runHistory interface {
+	geo.RunStore
+	geo.RunSearch
+}
+
+func newPgStores(pg *gorm.DB) (*pgStores, error) {
+	history, err := datastore.NewPgHistory(pg)
+	if err != nil {
+		return nil, err
+	}
+
+	// runs of loaders which crashed would be running forever
+	interrupted, err := history.InterruptStaleRuns(context.Background(), time.Now().Add(-*staleRun))
+	if err != nil {
+		return nil, err
+	}
+	if interrupted > 0 {
+		logrus.Warnf("%d stale import runs marked interrupted", interrupted)
+	}
+
+	stores := &pgStores{
+		geo:     datastore.NewPg(pg),
+		history: history,
+	}
+
+	switch *storerType {
+	case "insert":
+		stores.storer = stores.geo
+	case "copy":
+		stores.storer = datastore.NewPgCopy(pg)
+	default:
+		return nil, fmt.Errorf("unsupported storer: %s", *storerType)
+	}
+
+	return stores, nil
+}
+
+// newLoader will initialize loader for csv file at path, configured with flags.
+// Id of import (optional) is added to names of dead-letter and rejects files, so imports don't overwrite them.
+// Returned func releases loader resources, it must be called once Load is finished.
+func newLoader(ctx context.Context, stores *pgStores, id, path string) (jobs.Loader, func(), error) {
+	var closers []func()
+	closeAll := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+
+	ds := tracing.NewStorer(metrics.NewStorer(stores.storer))
+
+	validationRules, err := geo.ParseRules(*rules)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conflictPolicy, err := geo.ParseConflictPolicy(*conflict)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the same hash identifies csv file content of checkpoint and import run
+	checksum, err := checkpoint.Hash(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	checkpointFile := *checkpointPath
+	if *resume && checkpointFile == "" {
+		checkpointFile = path + ".checkpoint"
+	}
+
+	var checkpointer geo.Checkpointer
+	var last *checkpoint.Checkpoint
+	if checkpointFile != "" {
+		if *chunks > 1 {
+			return nil, nil, errors.New("checkpoints require csv file to be imported in order, they can't be used with -chunks")
+		}
+
+		cp := checkpoint.NewFile(checkpointFile, path, checksum)
+		checkpointer = cp
+
+		if *resume {
+			if last, err = cp.Last(); err != nil {
+				return nil, nil, err
+			}
+			if last == nil {
+				logrus.Warn("no checkpoint found for given csv file, starting from the beginning")
+			}
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	// resumed import reads only the rest of csv file
+	size := info.Size()
+	if last != nil {
+		size -= last.Offset
+	}
+
+	imp := importer.NewCsvImporter(path, *batch)
+	if last != nil {
+		imp = importer.NewCsvImporterFrom(path, *batch, last.Offset, last.Line)
+	}
+	if *chunks > 1 {
+		imp = importer.NewChunkedCsvImporter(path, *batch, *chunks)
+	}
+
+	cacheStore, closeCache, err := dedupCache(stores.geo)
+	if err != nil {
+		return nil, nil, err
+	}
+	closers = append(closers, closeCache)
+
+	ldr := geo.NewLoader(imp, ds, tracing.NewCache(metrics.NewCache(cacheStore)))
+	ldr.Validator = geo.NewValidator(validationRules)
+	ldr.Conflict = conflictPolicy
+	ldr.InFileWindow = *inFileWindow
+	ldr.Checkpointer = checkpointer
+	ldr.History = stores.history
+	// run of import job has the same id, so job can be found in history once it's evicted
+	ldr.RunId = id
+	ldr.Source = path
+	ldr.Checksum = checksum
+	ldr.Size = size
+	if *progressEvery > 0 {
+		ldr.ProgressInterval = *progressEvery
+	}
+	ldr.Subscribe(metrics.ObserveProgress())
+
+	ldr.Retry = &geo.RetryPolicy{
+		Attempts:   *retries,
+		Backoff:    *backoff,
+		MaxBackoff: *maxBackoff,
+		Jitter:     *jitter,
+		OnRetry:    metrics.ObserveRetry,
+	}
+
+	if *deadLetterPath != "" {
+		dl, err := deadletter.NewCsv(importFile(*deadLetterPath, id))
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+		closers = append(closers, func() {
+			if err := dl.Close(); err != nil {
+				logrus.Error(err)
+			}
+		})
+		ldr.DeadLetter = dl
+	}
+
+	if *rejectsPath != "" {
+		rj, err := rejects.NewFile(importFile(*rejectsPath, id))
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+		closers = append(closers, func() {
+			if err := rj.Close(); err != nil {
+				logrus.Error(err)
+			}
+		})
+		ldr.Rejecter = rj
+	}
+
+	if err = warmCache(ctx, ldr, stores.geo); err != nil {
+		closeAll()
+		return nil, nil, err
+	}
+
+	return observedLoader{Loader: ldr, path: path}, closeAll, nil
+}
+
+// importFile returns path of import output file, suffixed with import id if there is one (e.g. rejects-<id>.csv)
+func importFile(path, id string) string {
+	if id == "" {
+		return path
+	}
+
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + id + ext
+}
+
+// observedLoader will trace each import as a parent span of its batches
+type observedLoader struct {
+	jobs.Loader
+	path string
+}
+
+func (ldr observedLoader) Load(ctx context.Context, workers int) *geo.Report {
+	ctx, span := tracing.Start(ctx, "loader.Load", attribute.String("import.path", ldr.path), attribute.Int("import.workers", workers))
+	report := ldr.Loader.Load(ctx, workers)
+	span.SetAttributes(
+		attribute.String("import.run_id", report.RunId),
+		attribute.Int("import.read", report.Read),
+		attribute.Int("import.stored", report.Stored))
+	tracing.End(span, ctx.Err())
+
+	return report
+}
+
+// flushSpans will export remaining spans before loader exits
+func flushSpans(shutdown func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := shutdown(ctx); err != nil {
+		logrus.Error("failed to flush spans: ", err)
+	}
+}
+
+// serveMetrics will serve prometheus /metrics endpoint on -metrics-addr
+func serveMetrics() {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+
+	logrus.Infof("metrics listening on %s", *metricsAddr)
+	if err := http.ListenAndServe(*metricsAddr, mux); err != nil {
+		logrus.Error("metrics server failed: ", err)
+	}
+}
+
+// warmer is implemented by geo loader
+type warmer interface {
+	Warm(ctx context.Context, scanner geo.IpScanner, batchSize int) (*geo.WarmReport, error)
+	Diverged(ctx context.Context, scanner geo.IpScanner, sample int) (bool, error)
+}
+
+// warmCache will rebuild dedup cache from data store, according to -warm
+func warmCache(ctx context.Context, ldr warmer, scanner geo.IpScanner) error {
+	switch *warm {
+	case "none":
+		return nil
+	case "always":
+	case "auto":
+		diverged, err := ldr.Diverged(ctx, scanner, *warmSample)
+		if err != nil {
+			return err
+		}
+		if !diverged {
+			return nil
+		}
+		logrus.Warn("dedup cache is missing stored ips, warming it up")
+	default:
+		return fmt.Errorf("unsupported warm mode: %s", *warm)
+	}
+
+	_, err := ldr.Warm(ctx, scanner, *warmBatch)
+	return err
+}
+
+// purgeableCache is geo.Cache which can remove all keys in its namespace
+type purgeableCache interface {
+	geo.Cache
+	Purge() error
+}
+
+// dedupStore is data store used by dedup cache, to find or confirm already stored ips
+type dedupStore interface {
+	geo.Search
+	cache.Existing
+}
+
+// dedupCache will initialize cache selected with -dedup, redis keys are namespaced by dataset and run.
+// Only redis caches require redis to be reachable.
+// Returned func releases cache once import is finished: it closes redis client or saves in-process bloom filter snapshot.
+func dedupCache(store dedupStore) (purgeableCache, func(), error) {
+	conf := cache.NewRedisConfig()
+	conf.Host = *redisHost
+	conf.Prefix = cache.DedupPrefix(*dataset, *cacheRun)
+	conf.Ttl = *cacheTtl
+
+	var confirm geo.Search
+	if *bloomConfirm && store != nil {
+		confirm = tracing.NewSearch("datastore", store)
+	}
+
+	switch *dedup {
+	case "memory":
+		cacheStore := cache.NewInMemory()
+		cacheStore.Ttl = *cacheTtl
+		return cacheStore, func() {}, nil
+	case "datastore":
+		return cache.NewDatastore(store), func() {}, nil
+	case "redis":
+		cacheStore := cache.NewRedis(conf)
+		if err := cacheStore.Initialize(); err != nil {
+			return nil, nil, err
+		}
+		return cacheStore, closeRedis(cacheStore), nil
+	case "redis-bloom":
+		cacheStore, err := cache.NewRedisBloom(conf, *bloomCapacity, *bloomErrorRate)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err = cacheStore.Initialize(); err != nil {
+			return nil, nil, err
+		}
+		cacheStore.Confirm = confirm
+		return cacheStore, closeRedis(cacheStore), nil
+	case "bloom":
+		cacheStore, err := cache.NewBloom(*bloomCapacity, *bloomErrorRate)
+		if err != nil {
+			return nil, nil, err
+		}
+		cacheStore.Confirm = confirm
+		if *bloomSnapshot == "" {
+			return cacheStore, func() {}, nil
+		}
+
+		if err = cacheStore.Restore(*bloomSnapshot); err != nil {
+			return nil, nil, err
+		}
+		return cacheStore, func() {
+			if err := cacheStore.Snapshot(*bloomSnapshot); err != nil {
+				logrus.Error("failed to save bloom filter snapshot: ", err)
+			}
+		}, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported dedup cache: %s", *dedup)
+	}
+}
+
+// purgeLegacyKeys will remove dedup cache keys stored in redis before they were namespaced, Purge can't reach them
+func purgeLegacyKeys() {
 	conf := cache.NewRedisConfig()
 	conf.Host = *redisHost
+
 	cacheStore := cache.NewRedis(conf)
 	if err := cacheStore.Initialize(); err != nil {
 		logrus.Fatal(err)
 	}
-	ldr := geo.NewLoader(importer.NewCsvImporter(*csvPath, *batch), ds, cacheStore)
-	ldr.Load(impCtx, *workers)
+	defer closeRedis(cacheStore)()
+
+	removed, err := cacheStore.PurgeLegacy()
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	logrus.Infof("%d legacy dedup cache keys purged", removed)
+}
+
+// closeRedis will close connections of redis client
+func closeRedis(client io.Closer) func() {
+	return func() {
+		if err := client.Close(); err != nil {
+			logrus.Error("failed to close redis client: ", err)
+		}
+	}
+}
+
+// printProgress will log a single line with import progress
+func printProgress(p geo.Progress) {
+	if p.Done {
+		return
+	}
+
+	logrus.Infof("read %d | filtered %d | stored %d | %.0f rps | %.1f%% | eta %v",
+		p.Read, p.Filtered, p.Stored, p.Rps, p.Percent(), p.Eta)
+}
+
+func printReport(report *geo.Report) error {
+	switch *reportFormat {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "text":
+		logrus.Infof("=== import finished in %v ===\n"+
+			"- total records read = %d\n"+
+			"- parse errors = %d\n"+
+			"- invalid = %d\n"+
+			"- in-file duplicates = %d\n"+
+			"- already stored duplicates = %d\n"+
+			"- successfully stored = %d\n"+
+			"- skipped by data store = %d\n"+
+			"- failed to store = %d\n"+
+			"- bench = %.0f rps", report.Elapsed, report.Read, report.ParseErrors, report.Invalid,
+			report.InFileDuplicates, report.Duplicates, report.Stored, report.Skipped, report.Failed, report.Rps())
+
+		for reason, total := range report.Rejected {
+			logrus.Infof("- rejected %s = %d", reason, total)
+		}
+
+		for _, wr := range report.Workers {
+			logrus.Infof("=== store in db - worker %d ===\n"+
+				"- total records to store = %d\n"+
+				"- successfully stored = %d\n"+
+				"- skipped = %d\n"+
+				"- failed to store = %d\n", wr.Worker, wr.Received, wr.Stored, wr.Skipped, wr.Failed)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported report format: %s", *reportFormat)
+	}
 }
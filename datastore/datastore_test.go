@@ -0,0 +1,186 @@
+package datastore_test
+
+import (
+	"context"
+	"github.com/semirm-dev/findhotel/datastore"
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/semirm-dev/findhotel/geotest"
+	"github.com/semirm-dev/findhotel/internal/db"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestInMemory_Conformance(t *testing.T) {
+	geotest.TestStorer(t, func(t *testing.T) geo.Storer {
+		return datastore.NewInMemory()
+	})
+
+	geotest.TestSearch(t, func(t *testing.T, stored []*geo.Geo) geo.Search {
+		s := datastore.NewInMemory()
+		_, err := s.Store(context.Background(), stored, geo.ConflictSkip)
+		assert.Nil(t, err)
+
+		return s
+	})
+}
+
+func TestPg_Conformance(t *testing.T) {
+	geotest.TestStorer(t, func(t *testing.T) geo.Storer {
+		return datastore.NewPg(emptyPg(t))
+	})
+
+	geotest.TestSearch(t, func(t *testing.T, stored []*geo.Geo) geo.Search {
+		s := datastore.NewPg(emptyPg(t))
+		_, err := s.Store(context.Background(), stored, geo.ConflictSkip)
+		assert.Nil(t, err)
+
+		return s
+	})
+}
+
+func TestPgCopy_Conformance(t *testing.T) {
+	geotest.TestStorer(t, func(t *testing.T) geo.Storer {
+		return datastore.NewPgCopy(emptyPg(t))
+	})
+}
+
+func TestHistory_InterruptStaleRuns(t *testing.T) {
+	testTable := map[string]func(t *testing.T) geo.RunStore{
+		"inmemory": func(t *testing.T) geo.RunStore {
+			return datastore.NewInMemoryHistory()
+		},
+		"pg": func(t *testing.T) geo.RunStore {
+			pg := testPg(t)
+			assert.Nil(t, pg.Exec("DROP TABLE IF EXISTS import_runs").Error)
+
+			history, err := datastore.NewPgHistory(pg)
+			assert.Nil(t, err)
+
+			return history
+		},
+	}
+
+	for name, newHistory := range testTable {
+		t.Run(name, func(t *testing.T) {
+			history := newHistory(t)
+
+			now := time.Now().UTC().Truncate(time.Second)
+			finishedAt := now.Add(-time.Hour)
+			for _, run := range []*geo.Run{
+				{Id: "stale", StartedAt: now.Add(-2 * time.Hour), UpdatedAt: now.Add(-time.Hour), Status: geo.RunRunning},
+				{Id: "alive", StartedAt: now.Add(-2 * time.Hour), UpdatedAt: now, Status: geo.RunRunning},
+				{Id: "completed", StartedAt: now.Add(-2 * time.Hour), UpdatedAt: finishedAt, FinishedAt: &finishedAt, Status: geo.RunCompleted},
+			} {
+				assert.Nil(t, history.SaveRun(context.Background(), run))
+			}
+
+			interrupted, err := history.InterruptStaleRuns(context.Background(), now.Add(-time.Minute))
+			assert.Nil(t, err)
+			assert.Equal(t, 1, interrupted)
+
+			search := history.(geo.RunSearch)
+			for id, expected := range map[string]geo.RunStatus{"stale": geo.RunInterrupted, "alive": geo.RunRunning, "completed": geo.RunCompleted} {
+				run, err := search.RunById(context.Background(), id)
+				assert.Nil(t, err)
+				assert.Equal(t, expected, run.Status, id)
+			}
+
+			stale, err := search.RunById(context.Background(), "stale")
+			assert.Nil(t, err)
+			if assert.NotNil(t, stale.FinishedAt) {
+				assert.True(t, now.Add(-time.Hour).Equal(*stale.FinishedAt))
+			}
+		})
+	}
+}
+
+func TestPg_OverwriteRestoresRow(t *testing.T) {
+	testTable := map[string]func(pg *gorm.DB) geo.Storer{
+		"insert": func(pg *gorm.DB) geo.Storer { return datastore.NewPg(pg) },
+		"copy":   func(pg *gorm.DB) geo.Storer { return datastore.NewPgCopy(pg) },
+	}
+
+	for name, newStorer := range testTable {
+		t.Run(name, func(t *testing.T) {
+			pg := emptyPg(t)
+			s := newStorer(pg)
+
+			_, err := s.Store(context.Background(), []*geo.Geo{{Ip: "1.1.1.1", Country: "old"}}, geo.ConflictSkip)
+			assert.Nil(t, err)
+			assert.Nil(t, pg.Exec("UPDATE geos SET deleted_at = now(), network = NULL").Error)
+
+			stored, err := s.Store(context.Background(), []*geo.Geo{{Ip: "1.1.1.1", Country: "new"}}, geo.ConflictOverwrite)
+			assert.Nil(t, err)
+			assert.Equal(t, 1, stored)
+
+			found, err := datastore.NewPg(pg).ByIps(context.Background(), []string{"1.1.1.1"})
+			assert.Nil(t, err)
+			if assert.Contains(t, found, "1.1.1.1") {
+				assert.Equal(t, "new", found["1.1.1.1"].Country)
+			}
+
+			var withoutNetwork int64
+			assert.Nil(t, pg.Table("geos").Where("network IS NULL").Count(&withoutNetwork).Error)
+			assert.Zero(t, withoutNetwork)
+		})
+	}
+}
+
+func TestPg_MigrateInvalidIp(t *testing.T) {
+	pg := testPg(t)
+
+	// geos table of previous versions, with text ip column
+	assert.Nil(t, pg.Exec("DROP TABLE IF EXISTS geos, geos_invalid_ip").Error)
+	assert.Nil(t, pg.Exec("CREATE TABLE geos (id bigserial PRIMARY KEY, ip text UNIQUE, country text)").Error)
+	assert.Nil(t, pg.Exec("INSERT INTO geos (ip, country) VALUES ('1.1.1.1', 'valid'), ('999.1.1.1', 'invalid')").Error)
+
+	s := datastore.NewPg(pg)
+
+	found, err := s.ByIps(context.Background(), []string{"1.1.1.1"})
+	assert.Nil(t, err)
+	assert.Len(t, found, 1)
+
+	var quarantined []string
+	assert.Nil(t, pg.Raw("SELECT ip FROM geos_invalid_ip").Scan(&quarantined).Error)
+	assert.Equal(t, []string{"999.1.1.1"}, quarantined)
+
+	var stored int64
+	assert.Nil(t, pg.Table("geos").Count(&stored).Error)
+	assert.Equal(t, int64(1), stored)
+
+	// networks are filled in when ip column is converted
+	var withoutNetwork int64
+	assert.Nil(t, pg.Table("geos").Where("network IS NULL").Count(&withoutNetwork).Error)
+	assert.Zero(t, withoutNetwork)
+}
+
+// testPg will connect to TEST_PG_CONN database, test is skipped without it.
+// It must not point to database with real data.
+func testPg(t *testing.T) *gorm.DB {
+	conn := os.Getenv("TEST_PG_CONN")
+	if conn == "" {
+		t.Skip("TEST_PG_CONN is not set")
+	}
+
+	pg := db.PostgresDb(conn)
+	if pg == nil {
+		t.Skip("postgres is not available")
+	}
+
+	return pg
+}
+
+// emptyPg will connect to TEST_PG_CONN database and remove all *geo data from it, test is skipped without it.
+// It must not point to database with real data.
+func emptyPg(t *testing.T) *gorm.DB {
+	pg := testPg(t)
+
+	// table is created by data store
+	datastore.NewPg(pg)
+	assert.Nil(t, pg.Exec("TRUNCATE geos").Error)
+
+	return pg
+}
@@ -1,31 +1,176 @@
 package datastore
 
-import "github.com/semirm-dev/findhotel/geo"
+import (
+	"context"
+	"github.com/semirm-dev/findhotel/geo"
+	"net/netip"
+	"sync"
+)
 
 type inmemory struct {
+	mu   sync.RWMutex
 	data []*geo.Geo
+	// index holds position of each ip in data
+	index map[string]int
+	// networks holds every *geo data with valid ip or network, for the most specific match
+	networks *prefixTree
 }
 
 func NewInMemory() *inmemory {
-	return &inmemory{}
+	return &inmemory{
+		index:    make(map[string]int),
+		networks: newPrefixTree(),
+	}
+}
+
+func (storer *inmemory) Store(ctx context.Context, geoData []*geo.Geo, policy geo.ConflictPolicy) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	storer.mu.Lock()
+	defer storer.mu.Unlock()
+
+	var conflicts []string
+	stored := 0
+	for _, g := range geoData {
+		i, ok := storer.index[key(g.Ip)]
+		if ok && policy == geo.ConflictFail {
+			conflicts = append(conflicts, g.Ip)
+			continue
+		}
+		if !ok {
+			storer.index[key(g.Ip)] = len(storer.data)
+			storer.data = append(storer.data, storer.indexed(g))
+			stored++
+			continue
+		}
+
+		switch policy {
+		case geo.ConflictOverwrite:
+			storer.data[i] = storer.indexed(g)
+			stored++
+		case geo.ConflictOverwriteIfNewer:
+			if g.ObservedAt.After(storer.data[i].ObservedAt) {
+				storer.data[i] = storer.indexed(g)
+				stored++
+			}
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return stored, &geo.ConflictError{Ips: conflicts}
+	}
+
+	return stored, nil
+}
+
+// indexed will store copy of *geo data with its network, and index it by network. Given *geo data is not changed.
+func (storer *inmemory) indexed(g *geo.Geo) *geo.Geo {
+	stored := *g
+	if p, ok := stored.Prefix(); ok {
+		stored.Network = p.String()
+		storer.networks.insert(p, &stored)
+	}
+
+	return &stored
+}
+
+func (storer *inmemory) ByIp(ctx context.Context, ip string) (*geo.Geo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	storer.mu.RLock()
+	defer storer.mu.RUnlock()
+
+	return storer.byIp(ip), nil
+}
+
+// byIp will find *geo data with exactly the same ip, or the most specific network containing it
+func (storer *inmemory) byIp(ip string) *geo.Geo {
+	if i, ok := storer.index[key(ip)]; ok {
+		return storer.data[i]
+	}
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return nil
+	}
+
+	return storer.networks.lookup(addr.Unmap().WithZone(""))
 }
 
-func (storer *inmemory) Store(geoData []*geo.Geo) (int, error) {
-	storer.data = append(storer.data, geoData...)
+// key returns normalized ip, so that different text forms of the same address match.
+// Invalid ips are kept as they are.
+func key(ip string) string {
+	if normalized, err := geo.NormalizeIp(ip); err == nil {
+		return normalized
+	}
+
+	return ip
+}
+
+func (storer *inmemory) ByIps(ctx context.Context, ips []string) (map[string]*geo.Geo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	storer.mu.RLock()
+	defer storer.mu.RUnlock()
+
+	found := make(map[string]*geo.Geo)
+	for _, ip := range ips {
+		if g := storer.byIp(ip); g != nil {
+			found[ip] = g
+		}
+	}
 
-	return len(geoData), nil
+	return found, nil
 }
 
-func (storer *inmemory) ByIp(ip string) (*geo.Geo, error) {
+func (storer *inmemory) Existing(_ context.Context, ips []string) ([]string, error) {
+	storer.mu.RLock()
+	defer storer.mu.RUnlock()
+
+	existing := make([]string, 0)
+	for _, ip := range ips {
+		if _, ok := storer.index[key(ip)]; ok {
+			existing = append(existing, ip)
+		}
+	}
+
+	return existing, nil
+}
+
+func (storer *inmemory) ScanIps(ctx context.Context, batchSize int, fn func(ips []string) error) error {
+	storer.mu.RLock()
+	ips := make([]string, 0, len(storer.data))
 	for _, g := range storer.data {
-		if g.Ip == ip {
-			return g, nil
+		ips = append(ips, g.Ip)
+	}
+	storer.mu.RUnlock()
+
+	for start := 0; start < len(ips); start += batchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		end := start + batchSize
+		if end > len(ips) {
+			end = len(ips)
+		}
+		if err := fn(ips[start:end]); err != nil {
+			return err
 		}
 	}
 
-	return nil, nil
+	return nil
 }
 
 func (storer *inmemory) All() []*geo.Geo {
+	storer.mu.RLock()
+	defer storer.mu.RUnlock()
+
 	return storer.data
 }
@@ -0,0 +1,82 @@
+package datastore
+
+import (
+	"context"
+	"github.com/semirm-dev/findhotel/geo"
+	"sort"
+	"sync"
+	"time"
+)
+
+type inmemoryHistory struct {
+	mu   sync.RWMutex
+	runs map[string]*geo.Run
+}
+
+func NewInMemoryHistory() *inmemoryHistory {
+	return &inmemoryHistory{
+		runs: make(map[string]*geo.Run),
+	}
+}
+
+func (history *inmemoryHistory) SaveRun(_ context.Context, run *geo.Run) error {
+	history.mu.Lock()
+	defer history.mu.Unlock()
+
+	r := *run
+	history.runs[run.Id] = &r
+
+	return nil
+}
+
+func (history *inmemoryHistory) InterruptStaleRuns(_ context.Context, staleBefore time.Time) (int, error) {
+	history.mu.Lock()
+	defer history.mu.Unlock()
+
+	interrupted := 0
+	for id, r := range history.runs {
+		if r.Status != geo.RunRunning || !r.UpdatedAt.Before(staleBefore) {
+			continue
+		}
+
+		// returned runs are not changed
+		stale := *r
+		finishedAt := r.UpdatedAt
+		stale.FinishedAt = &finishedAt
+		stale.Status = geo.RunInterrupted
+		history.runs[id] = &stale
+		interrupted++
+	}
+
+	return interrupted, nil
+}
+
+func (history *inmemoryHistory) Runs(_ context.Context, offset, limit int) ([]*geo.Run, error) {
+	history.mu.RLock()
+	defer history.mu.RUnlock()
+
+	runs := make([]*geo.Run, 0, len(history.runs))
+	for _, r := range history.runs {
+		runs = append(runs, r)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartedAt.After(runs[j].StartedAt)
+	})
+
+	if offset >= len(runs) {
+		return []*geo.Run{}, nil
+	}
+	runs = runs[offset:]
+	if len(runs) > limit {
+		runs = runs[:limit]
+	}
+
+	return runs, nil
+}
+
+func (history *inmemoryHistory) RunById(_ context.Context, id string) (*geo.Run, error) {
+	history.mu.RLock()
+	defer history.mu.RUnlock()
+
+	return history.runs[id], nil
+}
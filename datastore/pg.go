@@ -1,41 +1,71 @@
 package datastore
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgconn"
 	"github.com/semirm-dev/findhotel/geo"
+	"github.com/semirm-dev/findhotel/tracing"
+	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
+	"strings"
 	"time"
 )
 
+// uniqueViolation is postgres error code for unique index violation
+const uniqueViolation = "23505"
+
+// overwrittenColumns are replaced when already stored ip is overwritten, soft deleted row is restored
+var overwrittenColumns = []string{"network", "country_code", "country", "city", "latitude", "longitude", "mystery_value", "observed_at", "updated_at", "deleted_at"}
+
+// olderSql matches stored rows observed before conflicting *geo data, unknown observed_at is older than anything
+const olderSql = "(" + geoTable + ".observed_at IS NULL OR " + geoTable + ".observed_at < excluded.observed_at)"
+
 type pgStore struct {
 	db *gorm.DB
 }
 
 type Geo struct {
-	Id           int    `gorm:"primarykey"`
-	Ip           string `gorm:"uniqueIndex"`
+	Id           int     `gorm:"primarykey"`
+	Ip           string  `gorm:"type:inet;uniqueIndex"`
+	Network      *string `gorm:"type:cidr"`
 	CountryCode  string
 	Country      string
 	City         string
 	Latitude     float64
 	Longitude    float64
 	MysteryValue int
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
-	DeletedAt    gorm.DeletedAt `gorm:"index"`
+	// ObservedAt is NULL for rows stored before it was tracked, they are older than any imported *geo data
+	ObservedAt *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  gorm.DeletedAt `gorm:"index"`
 }
 
 func NewPg(db *gorm.DB) *pgStore {
-	db.AutoMigrate(&Geo{})
+	if err := migrate(db); err != nil {
+		logrus.Fatal(err)
+	}
 
 	db.Logger = logger.Default.LogMode(logger.Silent)
+	traced(db)
 
 	return &pgStore{
 		db: db,
 	}
 }
 
-func (storer *pgStore) Store(geoData []*geo.Geo) (int, error) {
+// traced will register tracing plugin on db, it's registered once when db is shared by data stores
+func traced(db *gorm.DB) {
+	if err := db.Use(tracing.NewGormPlugin()); err != nil && !errors.Is(err, gorm.ErrRegistered) {
+		logrus.Fatal(err)
+	}
+}
+
+func (storer *pgStore) Store(ctx context.Context, geoData []*geo.Geo, policy geo.ConflictPolicy) (int, error) {
 	var bulk []*Geo
 
 	for _, g := range geoData {
@@ -46,19 +76,76 @@ func (storer *pgStore) Store(geoData []*geo.Geo) (int, error) {
 		return 0, nil
 	}
 
-	c := storer.db.Create(bulk)
+	if policy == geo.ConflictFail {
+		return storer.storeNew(ctx, bulk)
+	}
 
-	var err error
-	if int(c.RowsAffected) != len(bulk) {
-		err = c.Error
+	c := storer.db.WithContext(ctx).Clauses(onConflict(policy)).Create(bulk)
+
+	return int(c.RowsAffected), c.Error
+}
+
+// storeNew will insert *geo data whose ip is not stored yet, already stored ips are returned as *geo.ConflictError.
+// Ip stored concurrently, after it was checked, fails the whole batch with geo.ErrConflict.
+func (storer *pgStore) storeNew(ctx context.Context, bulk []*Geo) (int, error) {
+	ips := make([]string, 0, len(bulk))
+	for _, g := range bulk {
+		ips = append(ips, g.Ip)
 	}
 
-	return int(c.RowsAffected), err
+	var stored int
+	var conflicts []string
+	err := storer.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		// soft deleted rows still hold unique ip
+		if conflicts, err = existing(tx.Unscoped(), ips); err != nil {
+			return err
+		}
+
+		conflicting := make(map[string]struct{}, len(conflicts))
+		for _, ip := range conflicts {
+			conflicting[ip] = struct{}{}
+		}
+
+		insert := make([]*Geo, 0, len(bulk))
+		for _, g := range bulk {
+			if _, ok := conflicting[g.Ip]; !ok {
+				insert = append(insert, g)
+			}
+		}
+		if len(insert) == 0 {
+			return nil
+		}
+
+		c := tx.Create(insert)
+		stored = int(c.RowsAffected)
+		return c.Error
+	})
+	if err != nil {
+		return 0, conflictError(err)
+	}
+
+	if len(conflicts) > 0 {
+		return stored, &geo.ConflictError{Ips: conflicts}
+	}
+
+	return stored, nil
 }
 
-func (storer *pgStore) ByIp(ip string) (*geo.Geo, error) {
+// ByIp will find *geo data with exactly the same ip, or the most specific network containing it
+func (storer *pgStore) ByIp(ctx context.Context, ip string) (*geo.Geo, error) {
+	// invalid ip can not be stored in inet column
+	ip, ok := geo.NormalizeLookupIp(ip)
+	if !ok {
+		return nil, nil
+	}
+
 	var geoData *Geo
-	if result := storer.db.Where("ip", ip).Find(&geoData); result.Error != nil {
+	result := storer.db.WithContext(ctx).Where("ip = ?::inet OR network >>= ?::inet", ip, ip).
+		Order("masklen(network) DESC NULLS LAST").
+		Limit(1).
+		Find(&geoData)
+	if result.Error != nil {
 		return nil, result.Error
 	}
 	if geoData.Id == 0 {
@@ -67,26 +154,325 @@ func (storer *pgStore) ByIp(ip string) (*geo.Geo, error) {
 	return entityToGeo(geoData), nil
 }
 
+// lookupRow is *geo data matched for looked up ip
+type lookupRow struct {
+	QueryIp string
+	Geo     `gorm:"embedded"`
+}
+
+// ByIps will find the most specific match for each ip, in a single query
+func (storer *pgStore) ByIps(ctx context.Context, ips []string) (map[string]*geo.Geo, error) {
+	found := make(map[string]*geo.Geo)
+
+	// only valid ips can be matched, found *geo data is keyed by ip as it was given
+	requested := make(map[string][]string)
+	valid := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		normalized, ok := geo.NormalizeLookupIp(ip)
+		if !ok {
+			continue
+		}
+		if _, ok = requested[normalized]; !ok {
+			valid = append(valid, normalized)
+		}
+		requested[normalized] = append(requested[normalized], ip)
+	}
+	if len(valid) == 0 {
+		return found, nil
+	}
+
+	var rows []*lookupRow
+	result := storer.db.WithContext(ctx).Raw(`SELECT q.ip AS query_ip, g.*
+		FROM unnest(ARRAY[?]::text[]) AS q(ip)
+		CROSS JOIN LATERAL (
+			SELECT * FROM `+geoTable+`
+			WHERE deleted_at IS NULL AND (ip = q.ip::inet OR network >>= q.ip::inet)
+			ORDER BY masklen(network) DESC NULLS LAST
+			LIMIT 1
+		) g`, valid).Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	for _, r := range rows {
+		for _, ip := range requested[r.QueryIp] {
+			found[ip] = entityToGeo(&r.Geo)
+		}
+	}
+
+	return found, nil
+}
+
+// Existing will find which of given ips are already stored, with a single query
+func (storer *pgStore) Existing(ctx context.Context, ips []string) ([]string, error) {
+	return existing(storer.db.WithContext(ctx), ips)
+}
+
+// existing returns which of given ips are already stored in db
+func existing(db *gorm.DB, ips []string) ([]string, error) {
+	// invalid ip can not be stored in inet column, stored ips are returned as they were given
+	requested := make(map[string]string, len(ips))
+	valid := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		normalized, err := geo.NormalizeIp(ip)
+		if err != nil {
+			continue
+		}
+		requested[normalized] = ip
+		valid = append(valid, normalized)
+	}
+	if len(valid) == 0 {
+		return []string{}, nil
+	}
+
+	var stored []string
+	if result := db.Model(&Geo{}).Where("ip IN ?", valid).Pluck("ip", &stored); result.Error != nil {
+		return nil, result.Error
+	}
+
+	existing := make([]string, 0, len(stored))
+	for _, ip := range stored {
+		if normalized, err := geo.NormalizeIp(ip); err == nil {
+			existing = append(existing, requested[normalized])
+		}
+	}
+
+	return existing, nil
+}
+
+// ScanIps will scan all stored ips ordered by id, each batch is a single keyset paginated query
+func (storer *pgStore) ScanIps(ctx context.Context, batchSize int, fn func(ips []string) error) error {
+	lastId := 0
+	for {
+		var rows []*Geo
+		result := storer.db.WithContext(ctx).
+			Select("id", "ip").
+			Where("id > ?", lastId).
+			Order("id").
+			Limit(batchSize).
+			Find(&rows)
+		if result.Error != nil {
+			return result.Error
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		ips := make([]string, 0, len(rows))
+		for _, r := range rows {
+			ips = append(ips, r.Ip)
+		}
+		if err := fn(ips); err != nil {
+			return err
+		}
+
+		lastId = rows[len(rows)-1].Id
+	}
+}
+
+// migrate will create or update geos table, with GiST index for network lookups.
+// Text ip column from previous versions is converted to inet, and its network is filled in.
+func migrate(db *gorm.DB) error {
+	converted, err := migrateIpToInet(db)
+	if err != nil {
+		return fmt.Errorf("failed to convert ip column to inet: %w", err)
+	}
+
+	// networks are filled in once, when ip column is converted or network column is added, stores keep them up to date
+	backfill := converted || !db.Migrator().HasColumn(&Geo{}, "network")
+
+	if err := db.AutoMigrate(&Geo{}); err != nil {
+		return fmt.Errorf("failed to migrate geos table: %w", err)
+	}
+
+	// observed_at of rows stored before it was tracked is unknown, it used to default to migration time
+	if err := db.Exec(`ALTER TABLE ` + geoTable + ` ALTER COLUMN observed_at DROP DEFAULT`).Error; err != nil {
+		return fmt.Errorf("failed to drop observed_at default: %w", err)
+	}
+
+	if err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_geos_network ON ` + geoTable + ` USING gist (network inet_ops)`).Error; err != nil {
+		return fmt.Errorf("failed to create network index: %w", err)
+	}
+
+	if !backfill {
+		return nil
+	}
+
+	if err := db.Exec(`UPDATE ` + geoTable + ` SET network = network(ip) WHERE network IS NULL`).Error; err != nil {
+		return fmt.Errorf("failed to fill in networks: %w", err)
+	}
+
+	return nil
+}
+
+// migrateIpToInet will convert text ip column to inet, and report whether it was converted. Rows with ip
+// which is not valid inet would abort the conversion, so they are moved to invalidIpTable first.
+func migrateIpToInet(db *gorm.DB) (bool, error) {
+	if !db.Migrator().HasTable(&Geo{}) {
+		return false, nil
+	}
+
+	columns, err := db.Migrator().ColumnTypes(&Geo{})
+	if err != nil {
+		return false, err
+	}
+
+	for _, c := range columns {
+		if c.Name() == "ip" && !strings.EqualFold(c.DatabaseTypeName(), "inet") {
+			return true, db.Transaction(func(tx *gorm.DB) error {
+				if err := quarantineInvalidIps(tx); err != nil {
+					return err
+				}
+
+				return tx.Exec(`ALTER TABLE ` + geoTable + ` ALTER COLUMN ip TYPE inet USING ip::inet`).Error
+			})
+		}
+	}
+
+	return false, nil
+}
+
+// invalidIpTable keeps rows which could not be converted to inet, so they can be fixed and re-imported
+const invalidIpTable = "geos_invalid_ip"
+
+// quarantineInvalidIps will move rows with ip which can not be cast to inet into invalidIpTable
+func quarantineInvalidIps(tx *gorm.DB) error {
+	// cast which returns NULL instead of aborting on invalid ip
+	if err := tx.Exec(`CREATE OR REPLACE FUNCTION pg_temp.try_inet(ip text) RETURNS inet AS $$
+BEGIN
+	RETURN ip::inet;
+EXCEPTION WHEN others THEN
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE`).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Exec(`CREATE TABLE IF NOT EXISTS ` + invalidIpTable + ` (LIKE ` + geoTable + `)`).Error; err != nil {
+		return err
+	}
+
+	invalid := `pg_temp.try_inet(ip) IS NULL`
+	if err := tx.Exec(`INSERT INTO ` + invalidIpTable + ` SELECT * FROM ` + geoTable + ` WHERE ` + invalid).Error; err != nil {
+		return err
+	}
+
+	res := tx.Exec(`DELETE FROM ` + geoTable + ` WHERE ` + invalid)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected > 0 {
+		logrus.Warnf("moved %d rows with invalid ip to %s", res.RowsAffected, invalidIpTable)
+	}
+
+	return nil
+}
+
+// observedAt returns when *geo data was observed, nil if it's unknown
+func observedAt(geoData *geo.Geo) *time.Time {
+	if geoData.ObservedAt.IsZero() {
+		return nil
+	}
+
+	return &geoData.ObservedAt
+}
+
+// network returns canonical CIDR network of *geo data ip, nil if ip is not valid
+func network(geoData *geo.Geo) *string {
+	p, ok := geoData.Prefix()
+	if !ok {
+		return nil
+	}
+
+	n := p.String()
+	return &n
+}
+
 func geoToEntity(geoData *geo.Geo) *Geo {
 	return &Geo{
 		Ip:           geoData.Ip,
+		Network:      network(geoData),
 		CountryCode:  geoData.CountryCode,
 		Country:      geoData.Country,
 		City:         geoData.City,
 		Latitude:     geoData.Latitude,
 		Longitude:    geoData.Longitude,
 		MysteryValue: geoData.MysteryValue,
+		ObservedAt:   observedAt(geoData),
 	}
 }
 
 func entityToGeo(entity *Geo) *geo.Geo {
+	var n string
+	if entity.Network != nil {
+		n = *entity.Network
+	}
+
+	var observed time.Time
+	if entity.ObservedAt != nil {
+		observed = *entity.ObservedAt
+	}
+
 	return &geo.Geo{
 		Ip:           entity.Ip,
+		Network:      n,
 		CountryCode:  entity.CountryCode,
 		Country:      entity.Country,
 		City:         entity.City,
 		Latitude:     entity.Latitude,
 		Longitude:    entity.Longitude,
 		MysteryValue: entity.MysteryValue,
+		ObservedAt:   observed,
+	}
+}
+
+// onConflict builds ON CONFLICT clause for given policy, ConflictFail has no clause at all
+func onConflict(policy geo.ConflictPolicy) clause.OnConflict {
+	c := clause.OnConflict{
+		Columns: []clause.Column{{Name: "ip"}},
+	}
+
+	switch policy {
+	case geo.ConflictOverwrite:
+		c.DoUpdates = clause.AssignmentColumns(overwrittenColumns)
+	case geo.ConflictOverwriteIfNewer:
+		c.DoUpdates = clause.AssignmentColumns(overwrittenColumns)
+		c.Where = clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: olderSql},
+		}}
+	default:
+		c.DoNothing = true
+	}
+
+	return c
+}
+
+// onConflictSql is the same as onConflict, as plain sql
+func onConflictSql(policy geo.ConflictPolicy) string {
+	var set []string
+	for _, col := range overwrittenColumns {
+		set = append(set, col+" = excluded."+col)
+	}
+
+	switch policy {
+	case geo.ConflictFail:
+		return ""
+	case geo.ConflictOverwrite:
+		return "ON CONFLICT (ip) DO UPDATE SET " + strings.Join(set, ", ")
+	case geo.ConflictOverwriteIfNewer:
+		return "ON CONFLICT (ip) DO UPDATE SET " + strings.Join(set, ", ") +
+			" WHERE " + olderSql
+	default:
+		return "ON CONFLICT (ip) DO NOTHING"
 	}
 }
+
+// conflictError will wrap unique index violation as geo.ErrConflict
+func conflictError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return fmt.Errorf("%w: %v", geo.ErrConflict, err)
+	}
+
+	return err
+}
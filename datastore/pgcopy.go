@@ -0,0 +1,161 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/stdlib"
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/semirm-dev/findhotel/tracing"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	geoTable     = "geos"
+	stagingTable = "geos_staging"
+)
+
+// copyColumns are streamed with COPY into staging table, and then merged into geos table
+var copyColumns = []string{"ip", "network", "country_code", "country", "city", "latitude", "longitude", "mystery_value", "observed_at"}
+
+type pgCopyStore struct {
+	db *gorm.DB
+}
+
+// NewPgCopy will initialize Storer which streams *geo data batches with postgres COPY into staging table,
+// and then merges them into geos table. Rows with already stored ip are handled by geo.ConflictPolicy.
+func NewPgCopy(db *gorm.DB) *pgCopyStore {
+	if err := migrate(db); err != nil {
+		logrus.Fatal(err)
+	}
+
+	db.Logger = logger.Default.LogMode(logger.Silent)
+	traced(db)
+
+	return &pgCopyStore{
+		db: db,
+	}
+}
+
+// Store returns number of inserted or updated rows, the rest of the batch was skipped because of ip conflicts.
+// With geo.ConflictFail already stored ips are returned as *geo.ConflictError.
+func (storer *pgCopyStore) Store(ctx context.Context, geoData []*geo.Geo, policy geo.ConflictPolicy) (int, error) {
+	if len(geoData) == 0 {
+		return 0, nil
+	}
+
+	sqlDb, err := storer.db.DB()
+	if err != nil {
+		return 0, err
+	}
+
+	conn, err := sqlDb.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var inserted int64
+	var conflicts []string
+	err = conn.Raw(func(driverConn interface{}) error {
+		stdConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("unsupported driver connection %T, pgx is required", driverConn)
+		}
+
+		// COPY is executed on pgx connection, out of gorm callbacks
+		spanCtx, span := tracing.Start(ctx, "pg.copyAndMerge", attribute.Int("geo.batch_size", len(geoData)))
+		inserted, conflicts, err = copyAndMerge(spanCtx, stdConn.Conn(), geoData, policy)
+		span.SetAttributes(attribute.Int64("db.rows_affected", inserted))
+		tracing.End(span, err)
+
+		return err
+	})
+	if err != nil {
+		return 0, conflictError(err)
+	}
+
+	if len(conflicts) > 0 {
+		return int(inserted), &geo.ConflictError{Ips: conflicts}
+	}
+
+	return int(inserted), nil
+}
+
+// copyAndMerge returns number of inserted or updated rows, and already stored ips with geo.ConflictFail
+func copyAndMerge(ctx context.Context, conn *pgx.Conn, geoData []*geo.Geo, policy geo.ConflictPolicy) (int64, []string, error) {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = tx.Exec(ctx, `CREATE TEMP TABLE `+stagingTable+` (
+		ip text,
+		network text,
+		country_code text,
+		country text,
+		city text,
+		latitude decimal,
+		longitude decimal,
+		mystery_value bigint,
+		observed_at timestamptz
+	) ON COMMIT DROP`); err != nil {
+		return 0, nil, err
+	}
+
+	rows := pgx.CopyFromSlice(len(geoData), func(i int) ([]interface{}, error) {
+		g := geoData[i]
+		return []interface{}{g.Ip, network(g), g.CountryCode, g.Country, g.City, g.Latitude, g.Longitude, g.MysteryValue, observedAt(g)}, nil
+	})
+	if _, err = tx.CopyFrom(ctx, pgx.Identifier{stagingTable}, copyColumns, rows); err != nil {
+		return 0, nil, err
+	}
+
+	// with ConflictFail only new ips are inserted, already stored ones are reported back
+	var conflicts []string
+	newOnly := ""
+	if policy == geo.ConflictFail {
+		if conflicts, err = stagedConflicts(ctx, tx); err != nil {
+			return 0, nil, err
+		}
+		newOnly = `WHERE NOT EXISTS (SELECT 1 FROM ` + geoTable + ` g WHERE g.ip = ` + stagingTable + `.ip::inet)`
+	}
+
+	tag, err := tx.Exec(ctx, `INSERT INTO `+geoTable+` (ip, network, country_code, country, city, latitude, longitude, mystery_value, observed_at, created_at, updated_at)
+		SELECT DISTINCT ON (ip::inet) ip::inet, network::cidr, country_code, country, city, latitude, longitude, mystery_value, observed_at, now(), now()
+		FROM `+stagingTable+` `+newOnly+`
+		ORDER BY ip::inet `+onConflictSql(policy))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, nil, err
+	}
+
+	return tag.RowsAffected(), conflicts, nil
+}
+
+// stagedConflicts returns staged ips which are already stored, as they were given
+func stagedConflicts(ctx context.Context, tx pgx.Tx) ([]string, error) {
+	rows, err := tx.Query(ctx, `SELECT DISTINCT s.ip FROM `+stagingTable+` s JOIN `+geoTable+` g ON g.ip = s.ip::inet`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var conflicts []string
+	for rows.Next() {
+		var ip string
+		if err = rows.Scan(&ip); err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, ip)
+	}
+
+	return conflicts, rows.Err()
+}
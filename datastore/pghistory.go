@@ -0,0 +1,133 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/semirm-dev/findhotel/geo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"time"
+)
+
+type pgHistory struct {
+	db *gorm.DB
+}
+
+type ImportRun struct {
+	Id         string `gorm:"primarykey"`
+	Source     string
+	Checksum   string
+	StartedAt  time.Time `gorm:"index"`
+	FinishedAt *time.Time
+	Read       int
+	Accepted   int
+	Discarded  int
+	Status     string
+	// UpdatedAt is set by loader with each heartbeat
+	UpdatedAt time.Time `gorm:"autoUpdateTime:false"`
+}
+
+// NewPgHistory will initialize import runs history, backed by import_runs table
+func NewPgHistory(db *gorm.DB) (*pgHistory, error) {
+	if err := migrateHistory(db); err != nil {
+		return nil, err
+	}
+
+	db.Logger = logger.Default.LogMode(logger.Silent)
+	traced(db)
+
+	return &pgHistory{
+		db: db,
+	}, nil
+}
+
+func migrateHistory(db *gorm.DB) error {
+	// runs saved before updated_at was tracked were last saved when they started or finished
+	backfill := db.Migrator().HasTable(&ImportRun{}) && !db.Migrator().HasColumn(&ImportRun{}, "updated_at")
+
+	if err := db.AutoMigrate(&ImportRun{}); err != nil {
+		return fmt.Errorf("failed to migrate import_runs table: %w", err)
+	}
+
+	if !backfill {
+		return nil
+	}
+
+	if err := db.Exec(`UPDATE import_runs SET updated_at = COALESCE(finished_at, started_at) WHERE updated_at IS NULL`).Error; err != nil {
+		return fmt.Errorf("failed to fill in import runs updated_at: %w", err)
+	}
+
+	return nil
+}
+
+func (history *pgHistory) SaveRun(ctx context.Context, run *geo.Run) error {
+	return history.db.WithContext(ctx).Save(runToEntity(run)).Error
+}
+
+func (history *pgHistory) InterruptStaleRuns(ctx context.Context, staleBefore time.Time) (int, error) {
+	result := history.db.WithContext(ctx).Model(&ImportRun{}).
+		Where("status = ? AND updated_at < ?", string(geo.RunRunning), staleBefore).
+		UpdateColumns(map[string]interface{}{
+			"status":      string(geo.RunInterrupted),
+			"finished_at": gorm.Expr("updated_at"),
+		})
+
+	return int(result.RowsAffected), result.Error
+}
+
+func (history *pgHistory) Runs(ctx context.Context, offset, limit int) ([]*geo.Run, error) {
+	var entities []*ImportRun
+	if result := history.db.WithContext(ctx).Order("started_at desc").Offset(offset).Limit(limit).Find(&entities); result.Error != nil {
+		return nil, result.Error
+	}
+
+	runs := make([]*geo.Run, 0, len(entities))
+	for _, e := range entities {
+		runs = append(runs, entityToRun(e))
+	}
+
+	return runs, nil
+}
+
+func (history *pgHistory) RunById(ctx context.Context, id string) (*geo.Run, error) {
+	var entity *ImportRun
+	if result := history.db.WithContext(ctx).Where("id", id).First(&entity); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+
+	return entityToRun(entity), nil
+}
+
+func runToEntity(run *geo.Run) *ImportRun {
+	return &ImportRun{
+		Id:         run.Id,
+		Source:     run.Source,
+		Checksum:   run.Checksum,
+		StartedAt:  run.StartedAt,
+		FinishedAt: run.FinishedAt,
+		Read:       run.Read,
+		Accepted:   run.Accepted,
+		Discarded:  run.Discarded,
+		Status:     string(run.Status),
+		UpdatedAt:  run.UpdatedAt,
+	}
+}
+
+func entityToRun(entity *ImportRun) *geo.Run {
+	return &geo.Run{
+		Id:         entity.Id,
+		Source:     entity.Source,
+		Checksum:   entity.Checksum,
+		StartedAt:  entity.StartedAt,
+		FinishedAt: entity.FinishedAt,
+		Read:       entity.Read,
+		Accepted:   entity.Accepted,
+		Discarded:  entity.Discarded,
+		Status:     geo.RunStatus(entity.Status),
+		UpdatedAt:  entity.UpdatedAt,
+	}
+}
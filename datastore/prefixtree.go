@@ -0,0 +1,68 @@
+package datastore
+
+import (
+	"github.com/semirm-dev/findhotel/geo"
+	"net/netip"
+)
+
+// prefixTree is binary radix tree of networks, used for the most specific (longest prefix) match
+type prefixTree struct {
+	v4 *prefixNode
+	v6 *prefixNode
+}
+
+type prefixNode struct {
+	children [2]*prefixNode
+	geo      *geo.Geo
+}
+
+func newPrefixTree() *prefixTree {
+	return &prefixTree{
+		v4: &prefixNode{},
+		v6: &prefixNode{},
+	}
+}
+
+// insert will store *geo data for network, existing *geo data of the same network is replaced
+func (t *prefixTree) insert(p netip.Prefix, g *geo.Geo) {
+	node := t.root(p.Addr())
+	addr := p.Addr().AsSlice()
+
+	for i := 0; i < p.Bits(); i++ {
+		b := bit(addr, i)
+		if node.children[b] == nil {
+			node.children[b] = &prefixNode{}
+		}
+		node = node.children[b]
+	}
+
+	node.geo = g
+}
+
+// lookup returns *geo data of the most specific network containing addr, nil if there is none
+func (t *prefixTree) lookup(a netip.Addr) *geo.Geo {
+	node := t.root(a)
+	addr := a.AsSlice()
+
+	match := node.geo
+	for i := 0; i < a.BitLen() && node != nil; i++ {
+		node = node.children[bit(addr, i)]
+		if node != nil && node.geo != nil {
+			match = node.geo
+		}
+	}
+
+	return match
+}
+
+func (t *prefixTree) root(a netip.Addr) *prefixNode {
+	if a.Is4() {
+		return t.v4
+	}
+
+	return t.v6
+}
+
+func bit(addr []byte, i int) int {
+	return int(addr[i/8]>>(7-i%8)) & 1
+}
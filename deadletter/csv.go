@@ -0,0 +1,65 @@
+package deadletter
+
+import (
+	"encoding/csv"
+	"github.com/semirm-dev/findhotel/geo"
+	"os"
+	"sync"
+)
+
+// header is the same as in original data dump, so dead-lettered file can be replayed with csv importer
+var header = []string{"ip_address", "country_code", "country", "city", "latitude", "longitude", "mystery_value"}
+
+type csvDeadLetter struct {
+	mu   sync.Mutex
+	file *os.File
+	w    *csv.Writer
+}
+
+// NewCsv will create (or truncate) csv file at path and write dead-lettered *geo data into it
+func NewCsv(path string) (*csvDeadLetter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := csv.NewWriter(f)
+	if err = w.Write(header); err != nil {
+		f.Close()
+		return nil, err
+	}
+	w.Flush()
+
+	return &csvDeadLetter{
+		file: f,
+		w:    w,
+	}, w.Error()
+}
+
+func (dl *csvDeadLetter) Store(geoData []*geo.Geo, _ error) error {
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+
+	for _, g := range geoData {
+		if err := dl.w.Write(g.Fields()); err != nil {
+			return err
+		}
+	}
+	dl.w.Flush()
+
+	return dl.w.Error()
+}
+
+// Close will flush and close underlying csv file
+func (dl *csvDeadLetter) Close() error {
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+
+	dl.w.Flush()
+	if err := dl.w.Error(); err != nil {
+		dl.file.Close()
+		return err
+	}
+
+	return dl.file.Close()
+}
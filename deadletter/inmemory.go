@@ -0,0 +1,40 @@
+package deadletter
+
+import (
+	"github.com/semirm-dev/findhotel/geo"
+	"sync"
+)
+
+type inmemory struct {
+	mu   sync.Mutex
+	data []*geo.Geo
+	errs []error
+}
+
+func NewInMemory() *inmemory {
+	return &inmemory{}
+}
+
+func (dl *inmemory) Store(geoData []*geo.Geo, reason error) error {
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+
+	dl.data = append(dl.data, geoData...)
+	dl.errs = append(dl.errs, reason)
+
+	return nil
+}
+
+func (dl *inmemory) All() []*geo.Geo {
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+
+	return dl.data
+}
+
+func (dl *inmemory) Errors() []error {
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+
+	return dl.errs
+}
@@ -0,0 +1,42 @@
+package gateway
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Error codes are machine-readable reasons of failed requests
+const (
+	CodeMissingIp      = "missing_ip"
+	CodeInvalidIp      = "invalid_ip"
+	CodeInvalidRequest = "invalid_request"
+	CodeNotFound       = "not_found"
+	CodeStorageFailure = "storage_failure"
+)
+
+// Error is returned by all gateway handlers when request fails, wrapped in {"error": ...} envelope
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type errorEnvelope struct {
+	Error *Error `json:"error"`
+}
+
+// NotFound will respond with error document for unknown routes
+func NotFound() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		abortWithError(c, http.StatusNotFound, CodeNotFound, "route not found")
+	}
+}
+
+func abortWithError(c *gin.Context, status int, code, message string) {
+	c.AbortWithStatusJSON(status, &errorEnvelope{
+		Error: &Error{
+			Code:    code,
+			Message: message,
+		},
+	})
+}
@@ -1,21 +1,55 @@
 package gateway
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/semirm-dev/findhotel/geo"
 	"github.com/sirupsen/logrus"
 )
 
+// maxBatchIps limits number of ips in a single batch lookup
+const maxBatchIps = 1000
+
+type batchLookupRequest struct {
+	Ips []string `json:"ips"`
+}
+
+// batchLookupResult is *geo data for a single ip, Geo is nil when ip is not found
+type batchLookupResult struct {
+	Found bool     `json:"found"`
+	Geo   *geo.Geo `json:"geo,omitempty"`
+}
+
+type batchLookupResponse struct {
+	Results map[string]*batchLookupResult `json:"results"`
+}
+
+// GetGeoLocation will look up *geo data for ip given in query
 func GetGeoLocation(search geo.Search) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ip, _ := c.GetQuery("ip")
+		ip := strings.TrimSpace(c.Query("ip"))
+		if ip == "" {
+			abortWithError(c, http.StatusBadRequest, CodeMissingIp, "ip query parameter is required")
+			return
+		}
+		normalized, ok := geo.NormalizeLookupIp(ip)
+		if !ok {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidIp, fmt.Sprintf("invalid ip address: %s", ip))
+			return
+		}
 
-		geoData, err := search.ByIp(ip)
+		geoData, err := search.ByIp(c.Request.Context(), normalized)
 		if err != nil {
 			logrus.Error(err)
-			c.AbortWithStatus(http.StatusBadRequest)
+			abortWithError(c, http.StatusInternalServerError, CodeStorageFailure, "failed to look up ip")
+			return
+		}
+
+		if geoData == nil {
+			abortWithError(c, http.StatusNotFound, CodeNotFound, fmt.Sprintf("ip not found: %s", ip))
 			return
 		}
 
@@ -25,3 +59,53 @@ func GetGeoLocation(search geo.Search) gin.HandlerFunc {
 		)
 	}
 }
+
+// GetGeoLocations will look up *geo data for a list of ips, results are keyed by ip
+func GetGeoLocations(search geo.Search) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req *batchLookupRequest
+		if err := c.ShouldBindJSON(&req); err != nil || req == nil {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, `request body must be {"ips": [...]}`)
+			return
+		}
+		if len(req.Ips) == 0 || len(req.Ips) > maxBatchIps {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("number of ips must be between 1 and %d", maxBatchIps))
+			return
+		}
+		// results are keyed by requested ips, lookups are done with normalized ones
+		normalized := make(map[string]string, len(req.Ips))
+		lookup := make([]string, 0, len(req.Ips))
+		for _, ip := range req.Ips {
+			n, ok := geo.NormalizeLookupIp(ip)
+			if !ok {
+				abortWithError(c, http.StatusBadRequest, CodeInvalidIp, fmt.Sprintf("invalid ip address: %s", ip))
+				return
+			}
+			normalized[ip] = n
+			lookup = append(lookup, n)
+		}
+
+		found, err := search.ByIps(c.Request.Context(), lookup)
+		if err != nil {
+			logrus.Error(err)
+			abortWithError(c, http.StatusInternalServerError, CodeStorageFailure, "failed to look up ips")
+			return
+		}
+
+		resp := &batchLookupResponse{
+			Results: make(map[string]*batchLookupResult, len(req.Ips)),
+		}
+		for _, ip := range req.Ips {
+			g, ok := found[normalized[ip]]
+			resp.Results[ip] = &batchLookupResult{
+				Found: ok,
+				Geo:   g,
+			}
+		}
+
+		c.JSON(
+			http.StatusOK,
+			resp,
+		)
+	}
+}
@@ -1,7 +1,9 @@
 package gateway_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"github.com/semirm-dev/findhotel/datastore"
 	"github.com/semirm-dev/findhotel/gateway"
 	"github.com/semirm-dev/findhotel/geo"
@@ -10,12 +12,14 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 )
 
 func TestGetGeoLocation_IpExists_ReturnsGeo(t *testing.T) {
 	searchApi := datastore.NewInMemory()
-	stored, err := searchApi.Store([]*geo.Geo{
+	stored, err := searchApi.Store(context.Background(), []*geo.Geo{
 		{
 			Ip:           "1.1.1.1",
 			CountryCode:  "cc1",
@@ -34,7 +38,7 @@ func TestGetGeoLocation_IpExists_ReturnsGeo(t *testing.T) {
 			Longitude:    123.123,
 			MysteryValue: 123,
 		},
-	})
+	}, geo.ConflictSkip)
 	assert.Nil(t, err)
 	assert.Equal(t, 2, stored)
 
@@ -65,7 +69,7 @@ func TestGetGeoLocation_IpExists_ReturnsGeo(t *testing.T) {
 	assert.Equal(t, "cc1", resp.CountryCode)
 }
 
-func TestGetGeoLocation_IpNotExists_ReturnsNil(t *testing.T) {
+func TestGetGeoLocation_IpNotExists_ReturnsNotFound(t *testing.T) {
 	searchApi := datastore.NewInMemory()
 
 	router := web.NewRouter()
@@ -76,45 +80,216 @@ func TestGetGeoLocation_IpNotExists_ReturnsNil(t *testing.T) {
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
-	if w.Code != http.StatusOK {
-		t.Fail()
-	}
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assertError(t, w, gateway.CodeNotFound)
+}
 
-	respBody, err := ioutil.ReadAll(w.Body)
-	if err != nil {
-		t.Fail()
-	}
+func TestGetGeoLocation_IpNotGiven_ReturnsBadRequest(t *testing.T) {
+	searchApi := datastore.NewInMemory()
 
-	var resp *geo.Geo
-	if err = json.Unmarshal(respBody, &resp); err != nil {
-		t.Fail()
-	}
-	assert.Nil(t, resp)
+	router := web.NewRouter()
+	router.GET("geo", gateway.GetGeoLocation(searchApi))
+
+	req, _ := http.NewRequest("GET", "/geo", nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assertError(t, w, gateway.CodeMissingIp)
 }
 
-func TestGetGeoLocation_IpNotGiven_ReturnsNil(t *testing.T) {
+func TestGetGeoLocation_IpInvalid_ReturnsBadRequest(t *testing.T) {
 	searchApi := datastore.NewInMemory()
 
 	router := web.NewRouter()
 	router.GET("geo", gateway.GetGeoLocation(searchApi))
 
-	req, _ := http.NewRequest("GET", "/geo", nil)
+	req, _ := http.NewRequest("GET", "/geo?ip=999.1.1.1", nil)
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
-	if w.Code != http.StatusOK {
-		t.Fail()
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assertError(t, w, gateway.CodeInvalidIp)
+}
+
+func TestGetGeoLocation_StorageFails_ReturnsInternalError(t *testing.T) {
+	router := web.NewRouter()
+	router.GET("geo", gateway.GetGeoLocation(&failingSearch{}))
+
+	req, _ := http.NewRequest("GET", "/geo?ip=1.1.1.1", nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assertError(t, w, gateway.CodeStorageFailure)
+}
+
+type failingSearch struct{}
+
+func (s *failingSearch) ByIp(context.Context, string) (*geo.Geo, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (s *failingSearch) ByIps(context.Context, []string) (map[string]*geo.Geo, error) {
+	return nil, errors.New("connection refused")
+}
+
+func assertError(t *testing.T, w *httptest.ResponseRecorder, expectedCode string) {
+	var resp struct {
+		Error *gateway.Error `json:"error"`
 	}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.NotNil(t, resp.Error)
+	assert.Equal(t, expectedCode, resp.Error.Code)
+	assert.NotEmpty(t, resp.Error.Message)
+}
 
-	respBody, err := ioutil.ReadAll(w.Body)
-	if err != nil {
-		t.Fail()
+func TestGetGeoLocations_ReturnsFoundAndNotFound(t *testing.T) {
+	searchApi := datastore.NewInMemory()
+	_, err := searchApi.Store(context.Background(), []*geo.Geo{
+		{Ip: "1.1.1.1", CountryCode: "cc1"},
+		{Ip: "2.2.2.2", CountryCode: "cc2"},
+	}, geo.ConflictSkip)
+	assert.Nil(t, err)
+
+	router := web.NewRouter()
+	router.POST("geo/batch", gateway.GetGeoLocations(searchApi))
+
+	req, _ := http.NewRequest("POST", "/geo/batch", strings.NewReader(`{"ips": ["1.1.1.1", "2.2.2.2", "5.5.5.5"]}`))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Results map[string]struct {
+			Found bool     `json:"found"`
+			Geo   *geo.Geo `json:"geo"`
+		} `json:"results"`
 	}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Results, 3)
 
-	var resp *geo.Geo
-	if err = json.Unmarshal(respBody, &resp); err != nil {
-		t.Fail()
+	assert.True(t, resp.Results["1.1.1.1"].Found)
+	assert.Equal(t, "cc1", resp.Results["1.1.1.1"].Geo.CountryCode)
+	assert.True(t, resp.Results["2.2.2.2"].Found)
+	assert.Equal(t, "cc2", resp.Results["2.2.2.2"].Geo.CountryCode)
+	assert.False(t, resp.Results["5.5.5.5"].Found)
+	assert.Nil(t, resp.Results["5.5.5.5"].Geo)
+}
+
+func TestGetGeoLocations_InvalidBody_ReturnsBadRequest(t *testing.T) {
+	router := web.NewRouter()
+	router.POST("geo/batch", gateway.GetGeoLocations(datastore.NewInMemory()))
+
+	req, _ := http.NewRequest("POST", "/geo/batch", strings.NewReader(`{"ips": "1.1.1.1"}`))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assertError(t, w, gateway.CodeInvalidRequest)
+}
+
+func TestGetGeoLocation_IpInNetwork_ReturnsMostSpecificNetwork(t *testing.T) {
+	searchApi := datastore.NewInMemory()
+	_, err := searchApi.Store(context.Background(), []*geo.Geo{
+		{Ip: "10.0.0.0/8", CountryCode: "cc1"},
+		{Ip: "10.1.0.0/16", CountryCode: "cc2"},
+		{Ip: "10.1.1.1", CountryCode: "cc3"},
+	}, geo.ConflictSkip)
+	assert.Nil(t, err)
+
+	router := web.NewRouter()
+	router.GET("geo", gateway.GetGeoLocation(searchApi))
+
+	testTable := map[string]struct {
+		ip              string
+		expectedCountry string
+		expectedNetwork string
+	}{
+		"exact ip":              {ip: "10.1.1.1", expectedCountry: "cc3", expectedNetwork: "10.1.1.1/32"},
+		"most specific network": {ip: "10.1.2.3", expectedCountry: "cc2", expectedNetwork: "10.1.0.0/16"},
+		"wider network":         {ip: "10.2.2.2", expectedCountry: "cc1", expectedNetwork: "10.0.0.0/8"},
 	}
-	assert.Nil(t, resp)
+
+	for name, suite := range testTable {
+		t.Run(name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/geo?ip="+suite.ip, nil)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var resp *geo.Geo
+			assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, suite.expectedCountry, resp.CountryCode)
+			assert.Equal(t, suite.expectedNetwork, resp.Network)
+		})
+	}
+}
+
+func TestGetGeoLocation_IpInDifferentTextForm_ReturnsGeo(t *testing.T) {
+	searchApi := datastore.NewInMemory()
+	_, err := searchApi.Store(context.Background(), []*geo.Geo{
+		{Ip: "1.1.1.1", CountryCode: "cc1"},
+		{Ip: "2001:db8::1", CountryCode: "cc2"},
+		{Ip: "2001:db8:1::/48", CountryCode: "cc3"},
+	}, geo.ConflictSkip)
+	assert.Nil(t, err)
+
+	router := web.NewRouter()
+	router.GET("geo", gateway.GetGeoLocation(searchApi))
+	router.POST("geo/batch", gateway.GetGeoLocations(searchApi))
+
+	testTable := map[string]struct {
+		ip              string
+		expectedCountry string
+	}{
+		"v4-mapped v6":          {ip: "::ffff:1.1.1.1", expectedCountry: "cc1"},
+		"v6 with leading zeros": {ip: "2001:0db8:0000::1", expectedCountry: "cc2"},
+		"v6 upper case":         {ip: "2001:DB8::1", expectedCountry: "cc2"},
+		"v6 in network":         {ip: "2001:0db8:0001::abcd", expectedCountry: "cc3"},
+	}
+
+	for name, suite := range testTable {
+		t.Run(name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/geo?ip="+url.QueryEscape(suite.ip), nil)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var resp *geo.Geo
+			assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, suite.expectedCountry, resp.CountryCode)
+		})
+	}
+
+	t.Run("batch is keyed by given ips", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/geo/batch", strings.NewReader(`{"ips": ["::ffff:1.1.1.1", "2001:0db8:0000::1"]}`))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Results map[string]struct {
+				Found bool     `json:"found"`
+				Geo   *geo.Geo `json:"geo"`
+			} `json:"results"`
+		}
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.True(t, resp.Results["::ffff:1.1.1.1"].Found)
+		assert.Equal(t, "cc1", resp.Results["::ffff:1.1.1.1"].Geo.CountryCode)
+		assert.True(t, resp.Results["2001:0db8:0000::1"].Found)
+		assert.Equal(t, "cc2", resp.Results["2001:0db8:0000::1"].Geo.CountryCode)
+	})
 }
@@ -0,0 +1,143 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/semirm-dev/findhotel/proto/geopb"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type geoService struct {
+	geopb.UnimplementedGeoServiceServer
+	search geo.Search
+}
+
+// NewGeoService will initialize grpc GeoService, it uses geo.Search api to search for *geo data
+func NewGeoService(search geo.Search) *geoService {
+	return &geoService{
+		search: search,
+	}
+}
+
+func (svc *geoService) RegisterGrpcServer(server *grpc.Server) {
+	geopb.RegisterGeoServiceServer(server, svc)
+}
+
+func (svc *geoService) Lookup(ctx context.Context, req *geopb.LookupRequest) (*geopb.LookupResponse, error) {
+	resp, err := svc.lookup(ctx, req.GetIp())
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Found {
+		return nil, status.Errorf(codes.NotFound, "ip not found: %s", req.GetIp())
+	}
+
+	return resp, nil
+}
+
+func (svc *geoService) BatchLookup(ctx context.Context, req *geopb.BatchLookupRequest) (*geopb.BatchLookupResponse, error) {
+	if len(req.GetIps()) == 0 || len(req.GetIps()) > maxBatchIps {
+		return nil, status.Errorf(codes.InvalidArgument, "number of ips must be between 1 and %d", maxBatchIps)
+	}
+
+	// results are keyed by requested ips, lookups are done with normalized ones
+	normalized := make(map[string]string, len(req.GetIps()))
+	lookup := make([]string, 0, len(req.GetIps()))
+	for _, ip := range req.GetIps() {
+		n, ok := geo.NormalizeLookupIp(ip)
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid ip address: %s", ip)
+		}
+		normalized[ip] = n
+		lookup = append(lookup, n)
+	}
+
+	found, err := svc.search.ByIps(ctx, lookup)
+	if err != nil {
+		logrus.Error(err)
+		return nil, status.Error(codes.Internal, "failed to look up ips")
+	}
+
+	resp := &geopb.BatchLookupResponse{
+		Results: make(map[string]*geopb.LookupResponse, len(req.GetIps())),
+	}
+	for _, ip := range req.GetIps() {
+		resp.Results[ip] = lookupResponse(ip, found[normalized[ip]])
+	}
+
+	return resp, nil
+}
+
+func (svc *geoService) StreamLookup(stream geopb.GeoService_StreamLookupServer) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		resp, err := svc.lookup(stream.Context(), req.GetIp())
+		if status.Code(err) == codes.InvalidArgument {
+			// invalid ip still gets its result, so that results stay in order of requested ips
+			resp, err = &geopb.LookupResponse{Ip: req.GetIp(), Error: status.Convert(err).Message()}, nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err = stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// lookup will find *geo data for ip, not found ip is not an error
+func (svc *geoService) lookup(ctx context.Context, ip string) (*geopb.LookupResponse, error) {
+	if ip == "" {
+		return nil, status.Error(codes.InvalidArgument, "ip is required")
+	}
+
+	normalized, ok := geo.NormalizeLookupIp(ip)
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid ip address: %s", ip))
+	}
+
+	geoData, err := svc.search.ByIp(ctx, normalized)
+	if err != nil {
+		logrus.Error(err)
+		return nil, status.Error(codes.Internal, "failed to look up ip")
+	}
+
+	return lookupResponse(ip, geoData), nil
+}
+
+func lookupResponse(ip string, geoData *geo.Geo) *geopb.LookupResponse {
+	if geoData == nil {
+		return &geopb.LookupResponse{Ip: ip}
+	}
+
+	return &geopb.LookupResponse{
+		Ip:    ip,
+		Found: true,
+		Geo: &geopb.Geo{
+			Ip:           geoData.Ip,
+			CountryCode:  geoData.CountryCode,
+			Country:      geoData.Country,
+			City:         geoData.City,
+			Latitude:     geoData.Latitude,
+			Longitude:    geoData.Longitude,
+			MysteryValue: int64(geoData.MysteryValue),
+			Network:      geoData.Network,
+		},
+	}
+}
@@ -0,0 +1,138 @@
+package gateway_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/semirm-dev/findhotel/datastore"
+	"github.com/semirm-dev/findhotel/gateway"
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/semirm-dev/findhotel/proto/geopb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newGeoServiceClient will serve GeoService over in-memory connection
+func newGeoServiceClient(t *testing.T, search geo.Search) geopb.GeoServiceClient {
+	lis := bufconn.Listen(1024 * 1024)
+
+	srv := grpc.NewServer()
+	gateway.NewGeoService(search).RegisterGrpcServer(srv)
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return geopb.NewGeoServiceClient(conn)
+}
+
+func newGeoSearch(t *testing.T) geo.Search {
+	searchApi := datastore.NewInMemory()
+	_, err := searchApi.Store(context.Background(), []*geo.Geo{
+		{Ip: "1.1.1.1", CountryCode: "cc1", MysteryValue: 123},
+		{Ip: "10.0.0.0/8", CountryCode: "cc2"},
+	}, geo.ConflictSkip)
+	assert.Nil(t, err)
+
+	return searchApi
+}
+
+func TestGeoService_Lookup(t *testing.T) {
+	client := newGeoServiceClient(t, newGeoSearch(t))
+
+	testTable := map[string]struct {
+		ip              string
+		expectedCode    codes.Code
+		expectedCountry string
+		expectedNetwork string
+	}{
+		"ip exists":           {ip: "1.1.1.1", expectedCode: codes.OK, expectedCountry: "cc1", expectedNetwork: "1.1.1.1/32"},
+		"ip in network":       {ip: "10.1.1.1", expectedCode: codes.OK, expectedCountry: "cc2", expectedNetwork: "10.0.0.0/8"},
+		"v4-mapped v6 exists": {ip: "::ffff:1.1.1.1", expectedCode: codes.OK, expectedCountry: "cc1", expectedNetwork: "1.1.1.1/32"},
+		"ip not exists":       {ip: "5.5.5.5", expectedCode: codes.NotFound},
+		"ip not given":        {ip: "", expectedCode: codes.InvalidArgument},
+		"ip invalid":          {ip: "999.1.1.1", expectedCode: codes.InvalidArgument},
+	}
+
+	for name, suite := range testTable {
+		t.Run(name, func(t *testing.T) {
+			resp, err := client.Lookup(context.Background(), &geopb.LookupRequest{Ip: suite.ip})
+			assert.Equal(t, suite.expectedCode, status.Code(err))
+			if suite.expectedCode != codes.OK {
+				return
+			}
+
+			assert.True(t, resp.GetFound())
+			assert.Equal(t, suite.ip, resp.GetIp())
+			assert.Equal(t, suite.expectedCountry, resp.GetGeo().GetCountryCode())
+			assert.Equal(t, suite.expectedNetwork, resp.GetGeo().GetNetwork())
+		})
+	}
+}
+
+func TestGeoService_BatchLookup(t *testing.T) {
+	client := newGeoServiceClient(t, newGeoSearch(t))
+
+	resp, err := client.BatchLookup(context.Background(), &geopb.BatchLookupRequest{Ips: []string{"1.1.1.1", "10.2.2.2", "5.5.5.5"}})
+	assert.Nil(t, err)
+	assert.Len(t, resp.GetResults(), 3)
+	assert.Equal(t, "cc1", resp.GetResults()["1.1.1.1"].GetGeo().GetCountryCode())
+	assert.Equal(t, int64(123), resp.GetResults()["1.1.1.1"].GetGeo().GetMysteryValue())
+	assert.Equal(t, "cc2", resp.GetResults()["10.2.2.2"].GetGeo().GetCountryCode())
+	assert.False(t, resp.GetResults()["5.5.5.5"].GetFound())
+	assert.Nil(t, resp.GetResults()["5.5.5.5"].GetGeo())
+
+	_, err = client.BatchLookup(context.Background(), &geopb.BatchLookupRequest{Ips: []string{"1.1.1.1", "999.1.1.1"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGeoService_StreamLookup(t *testing.T) {
+	client := newGeoServiceClient(t, newGeoSearch(t))
+
+	stream, err := client.StreamLookup(context.Background())
+	assert.Nil(t, err)
+
+	ips := []string{"1.1.1.1", "5.5.5.5", "999.1.1.1", "", "10.2.2.2"}
+	for _, ip := range ips {
+		assert.Nil(t, stream.Send(&geopb.LookupRequest{Ip: ip}))
+	}
+	assert.Nil(t, stream.CloseSend())
+
+	var results []*geopb.LookupResponse
+	for range ips {
+		resp, err := stream.Recv()
+		assert.Nil(t, err)
+		results = append(results, resp)
+	}
+
+	assert.Equal(t, "cc1", results[0].GetGeo().GetCountryCode())
+	assert.False(t, results[1].GetFound())
+	assert.Equal(t, "5.5.5.5", results[1].GetIp())
+	assert.Empty(t, results[1].GetError())
+	assert.Equal(t, "999.1.1.1", results[2].GetIp())
+	assert.False(t, results[2].GetFound())
+	assert.Contains(t, results[2].GetError(), "invalid ip address")
+	assert.Equal(t, "", results[3].GetIp())
+	assert.Equal(t, "ip is required", results[3].GetError())
+	assert.Equal(t, "cc2", results[4].GetGeo().GetCountryCode())
+
+	_, err = stream.Recv()
+	assert.ErrorIs(t, err, io.EOF)
+}
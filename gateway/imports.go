@@ -0,0 +1,72 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultImportsLimit is number of import runs listed when limit is not given
+	defaultImportsLimit = 50
+	// maxImportsLimit limits number of import runs listed at once
+	maxImportsLimit = 500
+)
+
+// GetImports will list import runs history, the latest first.
+// It's paginated with limit (50 by default, at most 500) and offset query parameters.
+func GetImports(search geo.RunSearch) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultImportsLimit)))
+		if err != nil || limit < 1 || limit > maxImportsLimit {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("limit must be between 1 and %d", maxImportsLimit))
+			return
+		}
+
+		offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+		if err != nil || offset < 0 {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, "offset must not be negative")
+			return
+		}
+
+		runs, err := search.Runs(c.Request.Context(), offset, limit)
+		if err != nil {
+			logrus.Error(err)
+			abortWithError(c, http.StatusInternalServerError, CodeStorageFailure, "failed to get import runs")
+			return
+		}
+
+		c.JSON(
+			http.StatusOK,
+			runs,
+		)
+	}
+}
+
+// GetImport will get single import run by its id
+func GetImport(search geo.RunSearch) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		run, err := search.RunById(c.Request.Context(), id)
+		if err != nil {
+			logrus.Error(err)
+			abortWithError(c, http.StatusInternalServerError, CodeStorageFailure, "failed to get import run")
+			return
+		}
+
+		if run == nil {
+			abortWithError(c, http.StatusNotFound, CodeNotFound, fmt.Sprintf("import run not found: %s", id))
+			return
+		}
+
+		c.JSON(
+			http.StatusOK,
+			run,
+		)
+	}
+}
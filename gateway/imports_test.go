@@ -0,0 +1,133 @@
+package gateway_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/semirm-dev/findhotel/cache"
+	"github.com/semirm-dev/findhotel/datastore"
+	"github.com/semirm-dev/findhotel/gateway"
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/semirm-dev/findhotel/importer"
+	"github.com/semirm-dev/findhotel/internal/web"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetImports_ReturnsLoadedRuns(t *testing.T) {
+	history := datastore.NewInMemoryHistory()
+
+	ldr := geo.NewLoader(importer.NewInMemory([]*geo.Geo{{Ip: "1.1.1.1"}, {Ip: " "}}, 2), datastore.NewInMemory(), cache.NewInMemory())
+	ldr.History = history
+	ldr.Source = "data_dump.csv"
+	ldr.Checksum = "abc"
+	report := ldr.Load(context.Background(), 1)
+
+	router := web.NewRouter()
+	router.GET("imports", gateway.GetImports(history))
+	router.GET("imports/:id", gateway.GetImport(history))
+
+	req, _ := http.NewRequest("GET", "/imports", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var runs []*geo.Run
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &runs))
+	assert.Len(t, runs, 1)
+	assert.Equal(t, report.RunId, runs[0].Id)
+
+	req, _ = http.NewRequest("GET", "/imports/"+report.RunId, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var run *geo.Run
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &run))
+	assert.Equal(t, "data_dump.csv", run.Source)
+	assert.Equal(t, "abc", run.Checksum)
+	assert.Equal(t, geo.RunCompleted, run.Status)
+	assert.Equal(t, 2, run.Read)
+	assert.Equal(t, 1, run.Accepted)
+	assert.Equal(t, 1, run.Discarded)
+	assert.NotNil(t, run.FinishedAt)
+}
+
+func TestGetImport_NotExists_ReturnsNotFound(t *testing.T) {
+	router := web.NewRouter()
+	router.GET("imports/:id", gateway.GetImport(datastore.NewInMemoryHistory()))
+
+	req, _ := http.NewRequest("GET", "/imports/unknown", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assertError(t, w, gateway.CodeNotFound)
+}
+
+func TestGetImports_Paginated(t *testing.T) {
+	history := datastore.NewInMemoryHistory()
+	startedAt := time.Now()
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, history.SaveRun(context.Background(), &geo.Run{Id: fmt.Sprintf("run-%d", i), StartedAt: startedAt.Add(time.Duration(i) * time.Minute)}))
+	}
+
+	router := web.NewRouter()
+	router.GET("imports", gateway.GetImports(history))
+
+	testData := map[string]struct {
+		query    string
+		expected []string
+	}{
+		"default limit": {
+			query:    "",
+			expected: []string{"run-4", "run-3", "run-2", "run-1", "run-0"},
+		},
+		"limit": {
+			query:    "?limit=2",
+			expected: []string{"run-4", "run-3"},
+		},
+		"limit and offset": {
+			query:    "?limit=2&offset=2",
+			expected: []string{"run-2", "run-1"},
+		},
+		"offset past the last run": {
+			query:    "?offset=5",
+			expected: []string{},
+		},
+	}
+
+	for name, td := range testData {
+		t.Run(name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/imports"+td.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var runs []*geo.Run
+			assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &runs))
+			ids := make([]string, 0)
+			for _, r := range runs {
+				ids = append(ids, r.Id)
+			}
+			assert.Equal(t, td.expected, ids)
+		})
+	}
+}
+
+func TestGetImports_InvalidPagination_ReturnsBadRequest(t *testing.T) {
+	router := web.NewRouter()
+	router.GET("imports", gateway.GetImports(datastore.NewInMemoryHistory()))
+
+	for _, query := range []string{"?limit=0", "?limit=501", "?limit=abc", "?offset=-1", "?offset=abc"} {
+		req, _ := http.NewRequest("GET", "/imports"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		assertError(t, w, gateway.CodeInvalidRequest)
+	}
+}
@@ -0,0 +1,56 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/semirm-dev/findhotel/jobs"
+	"github.com/sirupsen/logrus"
+)
+
+// ImportWatcher will get import job and channel which is closed once job is updated, it's implemented by jobs manager
+type ImportWatcher interface {
+	Watch(id string) (*jobs.Job, <-chan struct{}, error)
+}
+
+// GetImportProgress will stream import job as server-sent events, "progress" event is sent with each update
+// and "done" event once import is finished
+func GetImportProgress(watcher ImportWatcher) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		if _, _, err := watcher.Watch(id); err != nil {
+			if errors.Is(err, jobs.ErrNotFound) {
+				abortWithError(c, http.StatusNotFound, CodeNotFound, fmt.Sprintf("import not found: %s", id))
+				return
+			}
+			logrus.Error(err)
+			abortWithError(c, http.StatusInternalServerError, CodeStorageFailure, "failed to get import")
+			return
+		}
+
+		for {
+			j, changed, err := watcher.Watch(id)
+			if err != nil {
+				logrus.Error(err)
+				return
+			}
+
+			if j.Finished() {
+				c.SSEvent("done", j)
+				c.Writer.Flush()
+				return
+			}
+			c.SSEvent("progress", j)
+			c.Writer.Flush()
+
+			select {
+			case <-changed:
+			case <-c.Request.Context().Done():
+				return
+			}
+		}
+	}
+}
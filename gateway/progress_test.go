@@ -0,0 +1,65 @@
+package gateway_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/semirm-dev/findhotel/cache"
+	"github.com/semirm-dev/findhotel/datastore"
+	"github.com/semirm-dev/findhotel/gateway"
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/semirm-dev/findhotel/importer"
+	"github.com/semirm-dev/findhotel/internal/web"
+	"github.com/semirm-dev/findhotel/jobs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetImportProgress_StreamsUntilFinished(t *testing.T) {
+	root := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "data_dump.csv"), []byte(
+		"ip_address,country_code,country,city,latitude,longitude,mystery_value\n"+
+			"1.1.1.1,NL,Netherlands,Amsterdam,52.37,4.89,100\n"), 0644))
+
+	manager := jobs.NewManager(root, func(_ context.Context, _, path string) (jobs.Loader, func(), error) {
+		return geo.NewLoader(importer.NewCsvImporter(path, 10), datastore.NewInMemory(), cache.NewInMemory()), func() {}, nil
+	})
+	started, err := manager.Start("data_dump.csv", 1)
+	assert.Nil(t, err)
+
+	router := web.NewRouter()
+	router.GET("imports/:id/progress", gateway.GetImportProgress(manager))
+
+	req, _ := http.NewRequest("GET", "/imports/"+started.Id+"/progress", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+	// the last event is sent once import is finished
+	events := strings.Split(strings.TrimSpace(w.Body.String()), "\n\n")
+	last := strings.SplitN(events[len(events)-1], "\n", 2)
+	assert.Equal(t, "event:done", last[0])
+
+	var finished *jobs.Job
+	assert.Nil(t, json.Unmarshal([]byte(strings.TrimPrefix(last[1], "data:")), &finished))
+	assert.Equal(t, started.Id, finished.Id)
+	assert.Equal(t, geo.RunCompleted, finished.Status)
+	assert.Equal(t, 1, finished.Progress.Stored)
+	assert.True(t, finished.Progress.Done)
+}
+
+func TestGetImportProgress_NotExists_ReturnsNotFound(t *testing.T) {
+	router := web.NewRouter()
+	router.GET("imports/:id/progress", gateway.GetImportProgress(jobs.NewManager(t.TempDir(), nil)))
+
+	req, _ := http.NewRequest("GET", "/imports/unknown/progress", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
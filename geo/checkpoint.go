@@ -0,0 +1,81 @@
+package geo
+
+import (
+	"github.com/sirupsen/logrus"
+	"sync"
+)
+
+// Checkpointer will persist position of the last *geo data row which is stored,
+// together with all rows before it, so interrupted import can be resumed from there
+type Checkpointer interface {
+	Commit(*Row) error
+}
+
+// filteredBatch is *geo data batch passed from filter to store workers.
+// seq is batch order in Importer, end is the last row of imported batch.
+type filteredBatch struct {
+	seq     int
+	geoData []*Geo
+	end     *Row
+}
+
+// committer keeps track of finished batches and commits position once all preceding batches are stored.
+// Importer must send batches in source order for positions to be meaningful.
+type committer struct {
+	mu           sync.Mutex
+	checkpointer Checkpointer
+	// next is seq of the first batch which is not yet finished
+	next int
+	// ends holds last rows of batches finished out of order
+	ends map[int]*Row
+	// failed is seq of the first batch that could not be stored, position never moves past it
+	failed int
+}
+
+func newCommitter(checkpointer Checkpointer) *committer {
+	return &committer{
+		checkpointer: checkpointer,
+		ends:         make(map[int]*Row),
+		failed:       -1,
+	}
+}
+
+// finish marks batch as done, stored tells whether it was successfully stored
+func (c *committer) finish(b *filteredBatch, stored bool) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.failed >= 0 && b.seq > c.failed {
+		return
+	}
+	if !stored {
+		c.failed = b.seq
+		return
+	}
+
+	c.ends[b.seq] = b.end
+
+	var last *Row
+	for c.next != c.failed {
+		end, ok := c.ends[c.next]
+		if !ok {
+			break
+		}
+		delete(c.ends, c.next)
+		c.next++
+		if end != nil {
+			last = end
+		}
+	}
+
+	if last == nil {
+		return
+	}
+	if err := c.checkpointer.Commit(last); err != nil {
+		logrus.Errorf("failed to commit checkpoint at line %d: %v", last.Line, err)
+	}
+}
@@ -0,0 +1,66 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ConflictPolicy decides what happens with *geo data whose ip is already stored
+type ConflictPolicy int
+
+const (
+	// ConflictSkip keeps already stored *geo data, new one is discarded as duplicate
+	ConflictSkip ConflictPolicy = iota
+	// ConflictOverwrite replaces already stored *geo data
+	ConflictOverwrite
+	// ConflictOverwriteIfNewer replaces already stored *geo data only if new one was observed later
+	ConflictOverwriteIfNewer
+	// ConflictFail treats already stored ip as an error, new *geo data is counted as failed
+	ConflictFail
+)
+
+// ErrConflict is returned by Storer when ConflictFail policy is used and ip is already stored
+var ErrConflict = errors.New("ip already stored")
+
+// ConflictError is returned by Storer with ConflictFail policy when some of the batch ips are already stored.
+// The rest of the batch is stored, it's matched by errors.Is(err, ErrConflict).
+type ConflictError struct {
+	// Ips are already stored ips, as they were given
+	Ips []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrConflict, strings.Join(e.Ips, ", "))
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+var conflictPolicyNames = map[ConflictPolicy]string{
+	ConflictSkip:             "skip",
+	ConflictOverwrite:        "overwrite",
+	ConflictOverwriteIfNewer: "overwrite-if-newer",
+	ConflictFail:             "fail",
+}
+
+// ParseConflictPolicy will parse conflict policy name: skip, overwrite, overwrite-if-newer or fail
+func ParseConflictPolicy(name string) (ConflictPolicy, error) {
+	for policy, n := range conflictPolicyNames {
+		if n == name {
+			return policy, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown conflict policy: %s", name)
+}
+
+func (policy ConflictPolicy) String() string {
+	return conflictPolicyNames[policy]
+}
+
+// overwrites tells whether already stored *geo data can be replaced, so duplicates must reach Storer
+func (policy ConflictPolicy) overwrites() bool {
+	return policy == ConflictOverwrite || policy == ConflictOverwriteIfNewer
+}
@@ -2,13 +2,14 @@ package geo
 
 import (
 	"context"
+	"errors"
 	"github.com/sirupsen/logrus"
-	"strings"
 	"sync"
 	"time"
 )
 
 type Geo struct {
+	// Ip is a single ip address or CIDR network
 	Ip           string  `json:"ip"`
 	CountryCode  string  `json:"country_code"`
 	Country      string  `json:"country"`
@@ -16,6 +17,13 @@ type Geo struct {
 	Latitude     float64 `json:"latitude"`
 	Longitude    float64 `json:"longitude"`
 	MysteryValue int     `json:"mystery_value"`
+	// Network is set by Search, it's the most specific stored network which matched looked up ip
+	Network string `json:"network,omitempty"`
+	// ObservedAt is when *geo data was known to be true, e.g. data dump creation time.
+	// It's used by ConflictOverwriteIfNewer policy, defaults to import start time.
+	ObservedAt time.Time `json:"-"`
+	// Row is set by Importer, it's used to report rejected rows
+	Row *Row `json:"-"`
 }
 
 // Importer will import *geo data from its source
@@ -23,24 +31,41 @@ type Importer interface {
 	Import(context.Context) *Imported
 }
 
-// Storer will store *geo data in data store
+// Storer will store *geo data in data store, already stored ips are handled according to ConflictPolicy.
+// Returned int is number of stored (inserted or updated) *geo data.
+// With ConflictFail the rest of the batch is stored, and already stored ips are returned as *ConflictError.
+// When only part of the batch is stored, not stored ips are returned as *PartialError.
+// Any other error means that none of the batch is stored.
 type Storer interface {
-	Store([]*Geo) (int, error)
+	Store(context.Context, []*Geo, ConflictPolicy) (int, error)
 }
 
-// Search will get *geo data from its source
+// Search will get *geo data from its source, ip is matched against the most specific stored network
 type Search interface {
-	ByIp(ip string) (*Geo, error)
+	ByIp(ctx context.Context, ip string) (*Geo, error)
+	// ByIps returns *geo data keyed by ip, ips which are not found are not in the map
+	ByIps(ctx context.Context, ips []string) (map[string]*Geo, error)
 }
 
 type CacheBucket map[string]string
 
+// KeySet is a set of cache keys
+type KeySet map[string]struct{}
+
+// Has will check if key is in the set, nil set is empty
+func (s KeySet) Has(key string) bool {
+	_, ok := s[key]
+	return ok
+}
+
 // Cache is used to keep track of all previously saved *geo data.
 // It's mainly used for validation to check if there are duplicate entries,
-// that is to make less database calls on *geo data insert
+// that is to make less database calls on *geo data insert.
+// Implementations must be safe for concurrent use.
 type Cache interface {
-	Store(CacheBucket) error
-	Get([]string) ([]string, error)
+	Store(context.Context, CacheBucket) error
+	// Get returns given keys which are already stored, missing keys are not in the set
+	Get(context.Context, []string) (KeySet, error)
 }
 
 // Imported presents each imported *geo data record/row
@@ -53,167 +78,430 @@ type loader struct {
 	importer Importer
 	storer   Storer
 	cache    Cache
+	// Retry is applied when Storer fails to store *geo data batch
+	Retry *RetryPolicy
+	// DeadLetter (optional) receives batches which failed to store after all re-tries
+	DeadLetter DeadLetter
+	// Rejecter (optional) receives every discarded row
+	Rejecter Rejecter
+	// Validator decides which *geo data is valid, by default only non-blank ip is required
+	Validator *Validator
+	// Conflict decides what happens with already stored ips, both in Cache and Storer
+	Conflict ConflictPolicy
+	// InFileWindow is number of the most recently accepted ips checked for in-file duplicates (100000 by default).
+	// Older duplicates are found by Cache once their ip is stored, or left to Storer (Conflict).
+	InFileWindow int
+	// Checkpointer (optional) persists position of stored *geo data, Importer must keep source order
+	Checkpointer Checkpointer
+	committer    *committer
+	// History (optional) persists each Load run, described by Source and Checksum
+	History RunStore
+	// RunId (optional) is id of the next Load run, unique id is generated by default
+	RunId    string
+	Source   string
+	Checksum string
+	// Heartbeat is how often running run is saved to History (1 minute by default),
+	// runs which are not saved anymore because their loader crashed are found with RunStore.InterruptStaleRuns
+	Heartbeat time.Duration
+	// ProgressInterval is how often progress is published to subscribers (1s by default), see Subscribe
+	ProgressInterval time.Duration
+	// Size (optional) is number of source bytes to be read, it's used to estimate progress ETA
+	Size        int64
+	subscribers []ProgressFunc
+	progress    *progress
+	// rejectMu guards Report.Rejected and Rejecter, rows are rejected by both filter and store workers
+	rejectMu sync.Mutex
 }
 
 // NewLoader will initialize *loader.
 // Loader will load *geo data from Importer and store it in data store using Storer
 func NewLoader(importer Importer, storer Storer, cache Cache) *loader {
 	return &loader{
-		importer: importer,
-		storer:   storer,
-		cache:    cache,
+		importer:  importer,
+		storer:    storer,
+		cache:     cache,
+		Retry:     NewRetryPolicy(),
+		Validator: NewValidator(0),
 	}
 }
 
-// Load will start loading *geo data from Importer to Storer
-func (ldr *loader) Load(ctx context.Context, workers int) {
+// Load will start loading *geo data from Importer to Storer.
+// Returned *Report holds statistics about accepted and discarded *geo data.
+func (ldr *loader) Load(ctx context.Context, workers int) *Report {
 	t := time.Now()
 
 	logrus.Info("import in progress...")
 
+	report := &Report{
+		Rejected: make(map[RejectReason]int),
+	}
+
+	ldr.committer = nil
+	if ldr.Checkpointer != nil {
+		ldr.committer = newCommitter(ldr.Checkpointer)
+	}
+
+	run := newRun(ldr.Source, ldr.Checksum)
+	if ldr.RunId != "" {
+		run.Id = ldr.RunId
+	}
+	report.RunId = run.Id
+	ldr.saveRun(ctx, run)
+	stopHeartbeat := ldr.heartbeat(ctx, run)
+
+	ldr.progress = &progress{}
+	stopProgress := ldr.publishProgress(run.Id, ldr.progress, t)
+
 	imported := ldr.importer.Import(ctx)
-	filtered := ldr.filterValidGeoData(ctx, imported)
+	filtered, filterDone := ldr.filterValidGeoData(ctx, imported, report)
 
 	var wg sync.WaitGroup
 	wg.Add(workers)
 	for i := 0; i < workers; i++ {
-		go ldr.storeGeoData(ctx, filtered, &wg, i)
+		wr := &WorkerReport{Worker: i}
+		report.Workers = append(report.Workers, wr)
+
+		go ldr.storeGeoData(ctx, filtered, &wg, report, wr)
 	}
 	wg.Wait()
+	<-filterDone
+
+	for _, wr := range report.Workers {
+		report.Stored += wr.Stored
+		report.Skipped += wr.Skipped
+		report.Failed += wr.Failed
+	}
+	report.Elapsed = time.Now().Sub(t)
+	stopProgress()
+	stopHeartbeat()
 
-	logrus.Infof("=== total time finished in %v ===", time.Now().Sub(t))
+	run.finish(report, ctx.Err() != nil)
+	ldr.saveRun(ctx, run)
+
+	return report
 }
 
-func (g *Geo) valid() bool {
-	if strings.TrimSpace(g.Ip) == "" {
-		return false
+// saveRun will save run even when ctx is cancelled, so that cancelled run is finished in History
+func (ldr *loader) saveRun(ctx context.Context, run *Run) {
+	if ldr.History == nil {
+		return
 	}
 
-	return true
+	run.UpdatedAt = time.Now()
+	if err := ldr.History.SaveRun(detached{ctx}, run); err != nil {
+		logrus.Errorf("failed to save import run %s: %v", run.Id, err)
+	}
 }
 
-// filterValidGeoData will sanitize *geo data. Duplicate and corrupted entries will be removed/skipped.
-func (ldr *loader) filterValidGeoData(ctx context.Context, imported *Imported) <-chan []*Geo {
-	filtered := make(chan []*Geo)
+// heartbeat will save running run every Heartbeat, until returned func is called
+func (ldr *loader) heartbeat(ctx context.Context, run *Run) func() {
+	if ldr.History == nil {
+		return func() {}
+	}
 
-	go func() {
-		b := 0
-		i := 0
-		e := 0
-		t := time.Now()
+	interval := ldr.Heartbeat
+	if interval <= 0 {
+		interval = defaultHeartbeat
+	}
 
-		defer func() {
-			close(filtered)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
 
-			finish := time.Now().Sub(t)
-			total := b + e
-			bench := func(total int, finish time.Duration) int {
-				if total == 0 || int(finish.Seconds()) == 0 {
-					return 0
-				}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
 
-				return total / int(finish.Seconds())
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				ldr.saveRun(ctx, run)
 			}
-			logrus.Infof("=== import csv ===\n"+
-				"- total records imported = %d\n"+
-				"- filtered and valid data = %d\n"+
-				"- bench = %d rps", total, i, bench(total, finish))
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-done
+	}
+}
+
+// filterValidGeoData will sanitize *geo data. Duplicate and corrupted entries will be removed/skipped.
+// Returned done channel is closed once filtering is finished and report is no longer written to.
+func (ldr *loader) filterValidGeoData(ctx context.Context, imported *Imported, report *Report) (<-chan *filteredBatch, <-chan struct{}) {
+	filtered := make(chan *filteredBatch)
+	done := make(chan struct{})
+
+	go func() {
+		defer func() {
+			close(filtered)
+			close(done)
 		}()
 
-		for {
+		startedAt := time.Now()
+		seq := 0
+		// recent are ips accepted from the last InFileWindow rows, so duplicates are found across batches too
+		recent := newRecentIps(ldr.InFileWindow)
+
+		batches, errs := imported.GeoDataBatch, imported.OnError
+		for batches != nil || errs != nil {
 			select {
-			case batch, ok := <-imported.GeoDataBatch:
+			case batch, ok := <-batches:
 				if !ok {
-					return
+					batches = nil
+					break
+				}
+				if len(batch) == 0 {
+					break
 				}
-				b += len(batch)
+				report.Read += len(batch)
+				ldr.progress.read.Add(int64(len(batch)))
+				ldr.progress.bytes.Add(rowBytes(batch))
 
-				// filter valid *geo objects and discard duplicate ips from this batch
+				fb := &filteredBatch{
+					seq: seq,
+					end: batch[len(batch)-1].Row,
+				}
+				seq++
+
+				// filter valid *geo objects and discard ips recently seen in this run
 				ipsFromCurrentBatch := make([]string, 0)
 				validBatch := make([]*Geo, 0)
 				for _, newGeo := range batch {
-					if newGeo.valid() && !exists(newGeo.Ip, ipsFromCurrentBatch) {
-						ipsFromCurrentBatch = append(ipsFromCurrentBatch, newGeo.Ip)
-						validBatch = append(validBatch, newGeo)
+					if rejection := ldr.Validator.Validate(newGeo); rejection != nil {
+						report.Invalid++
+						ldr.progress.invalid.Add(1)
+						ldr.reject(report, rejection)
+						continue
 					}
+					if recent.has(newGeo.Ip) {
+						report.InFileDuplicates++
+						ldr.progress.inFileDuplicates.Add(1)
+						ldr.reject(report, newGeo.reject(ReasonDuplicateInFile))
+						continue
+					}
+					recent.accept(newGeo.Ip)
+					if newGeo.ObservedAt.IsZero() {
+						newGeo.ObservedAt = startedAt
+					}
+					ipsFromCurrentBatch = append(ipsFromCurrentBatch, newGeo.Ip)
+					validBatch = append(validBatch, newGeo)
 				}
-
-				existingIps, err := ldr.cache.Get(ipsFromCurrentBatch)
-				if err != nil {
-					e += len(ipsFromCurrentBatch)
-					break
+				recent.next()
+
+				// previously persisted ips are left to Storer when they can be overwritten
+				var existingIps KeySet
+				if !ldr.Conflict.overwrites() {
+					var err error
+					existingIps, err = ldr.cache.Get(ctx, ipsFromCurrentBatch)
+					if err != nil {
+						logrus.Errorf("failed to get batch of %d from cache: %v", len(ipsFromCurrentBatch), err)
+						report.Failed += len(ipsFromCurrentBatch)
+						ldr.progress.failed.Add(int64(len(ipsFromCurrentBatch)))
+						ldr.committer.finish(fb, false)
+						ldr.deadLetter(validBatch, err)
+						break
+					}
 				}
 
-				// filter duplicate ips against previously persisted ips
-				cacheBucket := make(CacheBucket)
+				// filter duplicate ips against previously persisted ips,
+				// accepted ones are added to Cache only once they are stored
 				buf := make([]*Geo, 0)
 				for _, newGeo := range validBatch {
-					if exists(newGeo.Ip, existingIps) {
+					if existingIps.Has(newGeo.Ip) {
+						if ldr.Conflict == ConflictFail {
+							report.Failed++
+							ldr.progress.failed.Add(1)
+							ldr.reject(report, newGeo.rejectWith(ReasonDuplicateStored, ErrConflict))
+							continue
+						}
+						report.Duplicates++
+						ldr.progress.duplicates.Add(1)
+						ldr.reject(report, newGeo.reject(ReasonDuplicateStored))
 						continue
 					}
-					cacheBucket[newGeo.Ip] = newGeo.Ip
 					buf = append(buf, newGeo)
-					i++
 				}
 
-				if err = ldr.cache.Store(cacheBucket); err != nil {
-					e += len(cacheBucket)
-					return
+				// store workers take every filtered batch, even when ctx is done
+				fb.geoData = buf
+				ldr.progress.filtered.Add(int64(len(buf)))
+				filtered <- fb
+			case err, ok := <-errs:
+				if !ok {
+					errs = nil
+					break
 				}
-
-				filtered <- buf
-			case <-imported.OnError:
-				e++
+				report.Read++
+				report.ParseErrors++
+				ldr.progress.read.Add(1)
+				ldr.progress.parseErrors.Add(1)
+
+				var rejection *Rejection
+				if !errors.As(err, &rejection) {
+					rejection = &Rejection{Reason: ReasonMalformedCsv, Err: err}
+				}
+				ldr.progress.bytes.Add(fieldsBytes(rejection.Fields))
+				ldr.reject(report, rejection)
 			case <-ctx.Done():
 				return
 			}
 		}
 	}()
 
-	return filtered
+	return filtered, done
+}
+
+// reject will count discarded row by its reason and pass it to Rejecter
+func (ldr *loader) reject(report *Report, rejection *Rejection) {
+	ldr.rejectMu.Lock()
+	defer ldr.rejectMu.Unlock()
+
+	report.Rejected[rejection.Reason]++
+
+	if ldr.Rejecter == nil {
+		return
+	}
+	if err := ldr.Rejecter.Reject(rejection); err != nil {
+		logrus.Errorf("failed to report rejected row: %v", err)
+	}
 }
 
 // storeGeoData will store *geo data in database.
 // It must be last in the line, all data should already be checked and validated.
-func (ldr *loader) storeGeoData(ctx context.Context, geoData <-chan []*Geo, wg *sync.WaitGroup, worker int) {
-	b := 0
-	i := 0
-	e := 0
-
-	defer func(wg *sync.WaitGroup) {
-		wg.Done()
-
-		logrus.Infof("=== store in db - worker %d ===\n"+
-			"- total records to store = %d\n"+
-			"- successfully stored = %d\n"+
-			"- failed to store = %d\n", worker, b, i, e)
-	}(wg)
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case batch, ok := <-geoData:
-			if !ok {
-				return
-			}
-			b += len(batch)
+// Once ctx is done, remaining batches are not stored, they are counted as failed and dead-lettered.
+func (ldr *loader) storeGeoData(ctx context.Context, geoData <-chan *filteredBatch, wg *sync.WaitGroup, loadReport *Report, report *WorkerReport) {
+	defer wg.Done()
+
+	for fb := range geoData {
+		batch := fb.geoData
+		report.Received += len(batch)
+
+		if err := ctx.Err(); err != nil {
+			logrus.Warnf("worker %d did not store batch of %d: %v", report.Worker, len(batch), err)
+			report.Failed += len(batch)
+			ldr.progress.failed.Add(int64(len(batch)))
+			ldr.deadLetter(batch, err)
+			ldr.committer.finish(fb, false)
+			continue
+		}
 
-			stored, err := ldr.storer.Store(batch)
-			i += stored
-			if err != nil {
-				e += len(batch)
-			}
+		stored, unstored, err := ldr.storeWithRetry(ctx, batch)
+		report.Stored += stored
+		ldr.progress.stored.Add(int64(stored))
+
+		// only already stored ips failed, the rest of the batch is stored
+		rejected := 0
+		var conflict *ConflictError
+		if errors.As(err, &conflict) {
+			rejected = ldr.rejectConflicts(loadReport, batch, conflict)
+			err = nil
+		}
+
+		// not stored *geo data failed, the rest was accepted by Storer
+		failed := rejected + len(unstored)
+		report.Failed += failed
+		report.Skipped += len(batch) - stored - failed
+		ldr.progress.failed.Add(int64(failed))
+		ldr.progress.skipped.Add(int64(len(batch) - stored - failed))
+
+		if err == nil {
+			ldr.markStored(ctx, batch)
+		} else {
+			logrus.Errorf("worker %d failed to store %d of batch of %d: %v", report.Worker, len(unstored), len(batch), err)
+			ldr.markStored(ctx, without(batch, unstored))
+			ldr.deadLetter(unstored, err)
+		}
+		ldr.committer.finish(fb, err == nil)
+	}
+}
+
+// rejectConflicts will reject *geo data whose ip was already stored, returned is number of rejected rows
+func (ldr *loader) rejectConflicts(report *Report, batch []*Geo, conflict *ConflictError) int {
+	conflicting := make(map[string]struct{}, len(conflict.Ips))
+	for _, ip := range conflict.Ips {
+		conflicting[ip] = struct{}{}
+	}
+
+	rejected := 0
+	for _, g := range batch {
+		if _, ok := conflicting[g.Ip]; ok {
+			ldr.reject(report, g.rejectWith(ReasonDuplicateStored, ErrConflict))
+			rejected++
 		}
 	}
+
+	return rejected
+}
+
+// markStored will add ips of stored batch to Cache, so they are found as duplicates by the following imports.
+// Batch is already stored, so it's marked even if ctx is done.
+func (ldr *loader) markStored(ctx context.Context, batch []*Geo) {
+	if len(batch) == 0 {
+		return
+	}
+
+	bucket := make(CacheBucket, len(batch))
+	for _, g := range batch {
+		bucket[g.Ip] = g.Ip
+	}
+
+	if err := ldr.cache.Store(detached{ctx}, bucket); err != nil {
+		logrus.Errorf("failed to store batch of %d in cache: %v", len(bucket), err)
+	}
 }
 
-func exists(key string, keys []string) bool {
-	for _, k := range keys {
-		if k == key {
-			return true
+// without returns *geo data of batch which is not in excluded
+func without(batch, excluded []*Geo) []*Geo {
+	if len(excluded) == 0 {
+		return batch
+	}
+
+	skip := make(map[*Geo]struct{}, len(excluded))
+	for _, g := range excluded {
+		skip[g] = struct{}{}
+	}
+
+	rest := make([]*Geo, 0, len(batch)-len(excluded))
+	for _, g := range batch {
+		if _, ok := skip[g]; !ok {
+			rest = append(rest, g)
 		}
 	}
 
-	return false
+	return rest
+}
+
+// deadLetter will pass batch which could not be stored to DeadLetter, if there is one
+func (ldr *loader) deadLetter(batch []*Geo, err error) {
+	if ldr.DeadLetter == nil || len(batch) == 0 {
+		return
+	}
+
+	if dlErr := ldr.DeadLetter.Store(batch, err); dlErr != nil {
+		logrus.Errorf("failed to dead-letter batch of %d: %v", len(batch), dlErr)
+	}
+}
+
+// detached keeps values of its parent context (e.g. trace span), but it's never done
+type detached struct {
+	parent context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detached) Done() <-chan struct{} {
+	return nil
+}
+
+func (detached) Err() error {
+	return nil
+}
+
+func (d detached) Value(key interface{}) interface{} {
+	return d.parent.Value(key)
 }
@@ -2,12 +2,20 @@ package geo_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/semirm-dev/findhotel/cache"
 	"github.com/semirm-dev/findhotel/datastore"
+	"github.com/semirm-dev/findhotel/deadletter"
 	"github.com/semirm-dev/findhotel/geo"
 	"github.com/semirm-dev/findhotel/importer"
+	"github.com/semirm-dev/findhotel/rejects"
 	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
+	"time"
 )
 
 func TestLoader_Load(t *testing.T) {
@@ -87,3 +95,786 @@ func TestLoader_Load(t *testing.T) {
 		})
 	}
 }
+
+type inMemoryStorer interface {
+	geo.Storer
+	All() []*geo.Geo
+}
+
+// failingStorer fails first n calls to Store
+type failingStorer struct {
+	inMemoryStorer
+	fails int
+	calls int
+}
+
+func (s *failingStorer) Store(ctx context.Context, geoData []*geo.Geo, policy geo.ConflictPolicy) (int, error) {
+	s.calls++
+	if s.calls <= s.fails {
+		return 0, errors.New("store failed")
+	}
+
+	return s.inMemoryStorer.Store(ctx, geoData, policy)
+}
+
+func TestLoader_Load_Retry(t *testing.T) {
+	testTable := map[string]struct {
+		fails                   int
+		attempts                int
+		expectedStoredTotal     int
+		expectedDeadLetterTotal int
+	}{
+		"failed batch should be stored on re-try": {
+			fails:                   2,
+			attempts:                3,
+			expectedStoredTotal:     2,
+			expectedDeadLetterTotal: 0,
+		},
+		"batch should be dead-lettered when re-tries are exhausted": {
+			fails:                   3,
+			attempts:                3,
+			expectedStoredTotal:     0,
+			expectedDeadLetterTotal: 2,
+		},
+	}
+
+	impCtx, impCancel := context.WithCancel(context.Background())
+	defer impCancel()
+
+	for name, suite := range testTable {
+		t.Run(name, func(t *testing.T) {
+			given := []*geo.Geo{
+				{Ip: "1.1.1.1", CountryCode: "cc1"},
+				{Ip: "2.2.2.2", CountryCode: "cc2"},
+			}
+
+			mockImporter := importer.NewInMemory(given, 2)
+			mockStorer := &failingStorer{inMemoryStorer: datastore.NewInMemory(), fails: suite.fails}
+			mockDeadLetter := deadletter.NewInMemory()
+			mockCache := cache.NewInMemory()
+
+			ldr := geo.NewLoader(mockImporter, mockStorer, mockCache)
+			var retried []int
+			ldr.Retry = &geo.RetryPolicy{
+				Attempts: suite.attempts,
+				Backoff:  time.Millisecond,
+				OnRetry: func(attempt int, err error) {
+					assert.Error(t, err)
+					retried = append(retried, attempt)
+				},
+			}
+			ldr.DeadLetter = mockDeadLetter
+
+			ldr.Load(impCtx, 1)
+
+			assert.Equal(t, suite.attempts, mockStorer.calls)
+			assert.Equal(t, []int{1, 2}, retried)
+			assert.Equal(t, suite.expectedStoredTotal, len(mockStorer.All()))
+			assert.Equal(t, suite.expectedDeadLetterTotal, len(mockDeadLetter.All()))
+			// dead-lettered ips are not cached, so they can be re-imported
+			assert.Equal(t, suite.expectedStoredTotal, len(mockCache.All()))
+		})
+	}
+}
+
+// partialStorer stores only the first half of each batch in the first fails calls to Store
+type partialStorer struct {
+	inMemoryStorer
+	fails int
+	// calls are sizes of batches given to each Store call
+	calls []int
+}
+
+func (s *partialStorer) Store(ctx context.Context, geoData []*geo.Geo, policy geo.ConflictPolicy) (int, error) {
+	s.calls = append(s.calls, len(geoData))
+	if len(s.calls) > s.fails {
+		return s.inMemoryStorer.Store(ctx, geoData, policy)
+	}
+
+	half := len(geoData) / 2
+	stored, err := s.inMemoryStorer.Store(ctx, geoData[:half], policy)
+	if err != nil {
+		return stored, err
+	}
+
+	unstored := make([]string, 0)
+	for _, g := range geoData[half:] {
+		unstored = append(unstored, g.Ip)
+	}
+
+	return stored, &geo.PartialError{Ips: unstored, Err: errors.New("connection reset")}
+}
+
+func TestLoader_Load_PartialStore(t *testing.T) {
+	testTable := map[string]struct {
+		fails              int
+		expectedCalls      []int
+		expectedStored     int
+		expectedDeadLetter []string
+	}{
+		"only not stored half is re-tried": {
+			fails:              1,
+			expectedCalls:      []int{4, 2},
+			expectedStored:     4,
+			expectedDeadLetter: []string{},
+		},
+		"only not stored rows are dead-lettered": {
+			fails:              2,
+			expectedCalls:      []int{4, 2},
+			expectedStored:     3,
+			expectedDeadLetter: []string{"4.4.4.4"},
+		},
+	}
+
+	for name, suite := range testTable {
+		t.Run(name, func(t *testing.T) {
+			given := []*geo.Geo{{Ip: "1.1.1.1"}, {Ip: "2.2.2.2"}, {Ip: "3.3.3.3"}, {Ip: "4.4.4.4"}}
+
+			mockStorer := &partialStorer{inMemoryStorer: datastore.NewInMemory(), fails: suite.fails}
+			mockDeadLetter := deadletter.NewInMemory()
+			mockCache := cache.NewInMemory()
+
+			ldr := geo.NewLoader(importer.NewInMemory(given, 4), mockStorer, mockCache)
+			ldr.Retry = &geo.RetryPolicy{Attempts: 2, Backoff: time.Millisecond}
+			ldr.DeadLetter = mockDeadLetter
+
+			report := ldr.Load(context.Background(), 1)
+
+			assert.Equal(t, suite.expectedCalls, mockStorer.calls)
+			assert.Equal(t, suite.expectedStored, report.Stored)
+			assert.Equal(t, len(suite.expectedDeadLetter), report.Failed)
+			assert.Equal(t, 0, report.Skipped)
+			assert.Len(t, mockStorer.All(), suite.expectedStored)
+			assert.Len(t, mockCache.All(), suite.expectedStored)
+
+			deadLettered := make([]string, 0)
+			for _, g := range mockDeadLetter.All() {
+				deadLettered = append(deadLettered, g.Ip)
+			}
+			assert.Equal(t, suite.expectedDeadLetter, deadLettered)
+		})
+	}
+}
+
+func TestLoader_Load_RetryCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the first attempt fails and import is cancelled while waiting to re-try
+	mockStorer := &cancellingStorer{
+		inMemoryStorer: &failingStorer{inMemoryStorer: datastore.NewInMemory(), fails: 1},
+		cancel:         cancel,
+	}
+
+	retried := 0
+	ldr := geo.NewLoader(importer.NewInMemory([]*geo.Geo{{Ip: "1.1.1.1"}}, 1), mockStorer, cache.NewInMemory())
+	ldr.Retry = &geo.RetryPolicy{
+		Attempts: 3,
+		Backoff:  time.Hour,
+		OnRetry: func(int, error) {
+			retried++
+		},
+	}
+
+	report := ldr.Load(ctx, 1)
+
+	assert.Equal(t, 0, retried)
+	assert.Equal(t, 1, report.Failed)
+}
+
+func TestLoader_Load_Report(t *testing.T) {
+	// duplicate of 1.1.1.1 is in the next batch, so it's not yet stored nor cached
+	given := []*geo.Geo{
+		{Ip: "1.1.1.1", CountryCode: "cc1"},
+		{Ip: " ", CountryCode: "cc2"},
+		{Ip: "1.1.1.1", CountryCode: "cc1"},
+		{Ip: "2.2.2.2", CountryCode: "cc2"},
+		{Ip: "3.3.3.3", CountryCode: "cc3"},
+	}
+
+	mockCache := cache.NewInMemory()
+	err := mockCache.Store(context.Background(), geo.CacheBucket{"2.2.2.2": "2.2.2.2"})
+	assert.Nil(t, err)
+
+	ldr := geo.NewLoader(importer.NewInMemory(given, 2), datastore.NewInMemory(), mockCache)
+
+	report := ldr.Load(context.Background(), 2)
+
+	assert.Equal(t, 5, report.Read)
+	assert.Equal(t, 1, report.Invalid)
+	assert.Equal(t, 1, report.InFileDuplicates)
+	assert.Equal(t, 1, report.Duplicates)
+	assert.Equal(t, 2, report.Stored)
+	assert.Equal(t, 0, report.Failed)
+	assert.Equal(t, 3, report.Discarded())
+	assert.Len(t, report.Workers, 2)
+	assert.Equal(t, 2, report.Workers[0].Stored+report.Workers[1].Stored)
+}
+
+func TestLoader_Load_Rejects(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "data_dump.csv")
+	err := os.WriteFile(csvPath, []byte(
+		"ip_address,country_code,country,city,latitude,longitude,mystery_value\n"+
+			"1.1.1.1,SI,Nepal,DuBuquemouth,-84.87,7.20,7823011346\n"+
+			"2.2.2.2,CZ,Nicaragua,New Neva,bogus,-37.62,7301823115\n"+
+			"3.3.3.3,TL,Saudi Arabia,Gradymouth,-49.16,-86.05,bogus\n"+
+			",PY,Falkland Islands (Malvinas),,75.41,-144.69,0\n"+
+			"1.1.1.1,SI,Nepal,DuBuquemouth,-84.87,7.20,7823011346\n"+
+			"4.4.4.4,LI,Guyana\n"), 0644)
+	assert.Nil(t, err)
+
+	testTable := map[string]struct {
+		importer geo.Importer
+		// ordered importer reads rows in file order, so the second of duplicate rows is rejected
+		ordered bool
+	}{
+		"sequential": {
+			importer: importer.NewCsvImporter(csvPath, 10),
+			ordered:  true,
+		},
+		"chunked": {
+			importer: importer.NewChunkedCsvImporter(csvPath, 2, 3),
+		},
+	}
+
+	for name, suite := range testTable {
+		t.Run(name, func(t *testing.T) {
+			mockRejecter := rejects.NewInMemory()
+
+			ldr := geo.NewLoader(suite.importer, datastore.NewInMemory(), cache.NewInMemory())
+			ldr.Rejecter = mockRejecter
+
+			report := ldr.Load(context.Background(), 1)
+
+			assert.Equal(t, 6, report.Read)
+			assert.Equal(t, 1, report.Stored)
+			assert.Equal(t, map[geo.RejectReason]int{
+				geo.ReasonBadLatitude:     1,
+				geo.ReasonBadMysteryValue: 1,
+				geo.ReasonEmptyIp:         1,
+				geo.ReasonMalformedCsv:    1,
+				geo.ReasonDuplicateInFile: 1,
+			}, report.Rejected)
+
+			expected := map[int]geo.RejectReason{
+				3: geo.ReasonBadLatitude,
+				4: geo.ReasonBadMysteryValue,
+				5: geo.ReasonEmptyIp,
+				7: geo.ReasonMalformedCsv,
+			}
+			if suite.ordered {
+				expected[6] = geo.ReasonDuplicateInFile
+			}
+
+			rejections := mockRejecter.All()
+			assert.Len(t, rejections, 5)
+			for _, r := range rejections {
+				assert.NotEmpty(t, r.Fields, "line %d", r.Line)
+				// chunks are parsed concurrently, so either of duplicate rows can be read first
+				if !suite.ordered && (r.Line == 2 || r.Line == 6) {
+					assert.Equal(t, geo.ReasonDuplicateInFile, r.Reason, "line %d", r.Line)
+					continue
+				}
+				assert.Equal(t, expected[r.Line], r.Reason, "line %d", r.Line)
+			}
+		})
+	}
+}
+
+func TestLoader_Load_Conflict(t *testing.T) {
+	stale := &geo.Geo{Ip: "1.1.1.1", City: "stale", ObservedAt: time.Now().Add(-time.Hour)}
+	future := &geo.Geo{Ip: "2.2.2.2", City: "future", ObservedAt: time.Now().Add(time.Hour)}
+
+	testTable := map[string]struct {
+		policy         geo.ConflictPolicy
+		expectedCities map[string]string
+		expectedStored int
+		expectedFailed int
+	}{
+		"skip keeps stored data": {
+			policy:         geo.ConflictSkip,
+			expectedCities: map[string]string{"1.1.1.1": "stale", "2.2.2.2": "future"},
+			expectedStored: 0,
+		},
+		"overwrite replaces stored data": {
+			policy:         geo.ConflictOverwrite,
+			expectedCities: map[string]string{"1.1.1.1": "corrected", "2.2.2.2": "corrected"},
+			expectedStored: 2,
+		},
+		"overwrite-if-newer replaces only older stored data": {
+			policy:         geo.ConflictOverwriteIfNewer,
+			expectedCities: map[string]string{"1.1.1.1": "corrected", "2.2.2.2": "future"},
+			expectedStored: 1,
+		},
+		"fail keeps stored data and counts failures": {
+			policy:         geo.ConflictFail,
+			expectedCities: map[string]string{"1.1.1.1": "stale", "2.2.2.2": "future"},
+			expectedStored: 0,
+			expectedFailed: 2,
+		},
+	}
+
+	for name, suite := range testTable {
+		t.Run(name, func(t *testing.T) {
+			mockStorer := datastore.NewInMemory()
+			_, err := mockStorer.Store(context.Background(), []*geo.Geo{stale, future}, geo.ConflictSkip)
+			assert.Nil(t, err)
+
+			mockCache := cache.NewInMemory()
+			err = mockCache.Store(context.Background(), geo.CacheBucket{"1.1.1.1": "1.1.1.1", "2.2.2.2": "2.2.2.2"})
+			assert.Nil(t, err)
+
+			given := []*geo.Geo{
+				{Ip: "1.1.1.1", City: "corrected"},
+				{Ip: "2.2.2.2", City: "corrected"},
+			}
+
+			ldr := geo.NewLoader(importer.NewInMemory(given, 2), mockStorer, mockCache)
+			ldr.Conflict = suite.policy
+
+			report := ldr.Load(context.Background(), 1)
+			assert.Equal(t, suite.expectedStored, report.Stored)
+			assert.Equal(t, suite.expectedFailed, report.Failed)
+
+			for ip, city := range suite.expectedCities {
+				stored, err := mockStorer.ByIp(context.Background(), ip)
+				assert.Nil(t, err)
+				assert.Equal(t, city, stored.City, ip)
+			}
+		})
+	}
+}
+
+func TestLoader_Load_ConflictFail(t *testing.T) {
+	mockStorer := datastore.NewInMemory()
+	_, err := mockStorer.Store(context.Background(), []*geo.Geo{{Ip: "1.1.1.1", City: "stored"}}, geo.ConflictSkip)
+	assert.Nil(t, err)
+
+	// cache doesn't know about stored ip, so conflict is found by Storer
+	mockCache := cache.NewInMemory()
+	mockRejecter := rejects.NewInMemory()
+
+	given := []*geo.Geo{{Ip: "1.1.1.1", City: "conflicting"}, {Ip: "3.3.3.3", City: "new"}}
+	ldr := geo.NewLoader(importer.NewInMemory(given, 2), mockStorer, mockCache)
+	ldr.Conflict = geo.ConflictFail
+	ldr.Rejecter = mockRejecter
+
+	report := ldr.Load(context.Background(), 1)
+	assert.Equal(t, 1, report.Stored)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 0, report.Skipped)
+	assert.Equal(t, 1, report.Rejected[geo.ReasonDuplicateStored])
+
+	rejections := mockRejecter.All()
+	if assert.Len(t, rejections, 1) {
+		assert.True(t, errors.Is(rejections[0], geo.ErrConflict))
+	}
+
+	stored, err := mockStorer.ByIp(context.Background(), "1.1.1.1")
+	assert.Nil(t, err)
+	assert.Equal(t, "stored", stored.City)
+	stored, err = mockStorer.ByIp(context.Background(), "3.3.3.3")
+	assert.Nil(t, err)
+	assert.NotNil(t, stored)
+	assert.Len(t, mockCache.All(), 2)
+}
+
+// ipFailingStorer fails every batch containing given ip
+type ipFailingStorer struct {
+	inMemoryStorer
+	ip string
+}
+
+func (s *ipFailingStorer) Store(ctx context.Context, geoData []*geo.Geo, policy geo.ConflictPolicy) (int, error) {
+	for _, g := range geoData {
+		if g.Ip == s.ip {
+			return 0, errors.New("store failed")
+		}
+	}
+
+	return s.inMemoryStorer.Store(ctx, geoData, policy)
+}
+
+// cancellingStorer cancels import once the first batch is stored
+type cancellingStorer struct {
+	inMemoryStorer
+	cancel context.CancelFunc
+}
+
+func (s *cancellingStorer) Store(ctx context.Context, geoData []*geo.Geo, policy geo.ConflictPolicy) (int, error) {
+	defer s.cancel()
+
+	return s.inMemoryStorer.Store(ctx, geoData, policy)
+}
+
+func TestLoader_Load_Cancel(t *testing.T) {
+	given := []*geo.Geo{{Ip: "1.1.1.1"}, {Ip: "2.2.2.2"}, {Ip: "3.3.3.3"}, {Ip: "4.4.4.4"}, {Ip: "5.5.5.5"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockStorer := datastore.NewInMemory()
+	mockCache := cache.NewInMemory()
+	mockDeadLetter := deadletter.NewInMemory()
+
+	ldr := geo.NewLoader(importer.NewInMemory(given, 1), &cancellingStorer{inMemoryStorer: mockStorer, cancel: cancel}, mockCache)
+	ldr.DeadLetter = mockDeadLetter
+
+	report := ldr.Load(ctx, 2)
+
+	// how many batches are read before import is cancelled depends on scheduling,
+	// but each one read is either stored or failed and dead-lettered, only stored ones are cached
+	assert.GreaterOrEqual(t, report.Stored, 1)
+	assert.Equal(t, report.Read, report.Stored+report.Failed)
+	assert.Len(t, mockDeadLetter.All(), report.Failed)
+	assert.Len(t, mockStorer.All(), report.Stored)
+	assert.Len(t, mockCache.All(), report.Stored)
+}
+
+type lastRowCheckpointer struct {
+	last *geo.Row
+}
+
+func (c *lastRowCheckpointer) Commit(row *geo.Row) error {
+	c.last = row
+	return nil
+}
+
+func TestLoader_Load_Checkpoint(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "data_dump.csv")
+	err := os.WriteFile(csvPath, []byte(
+		"ip_address,country_code,country,city,latitude,longitude,mystery_value\n"+
+			"1.1.1.1,SI,Nepal,DuBuquemouth,-84.87,7.20,1\n"+
+			"2.2.2.2,CZ,Nicaragua,New Neva,-68.31,-37.62,2\n"+
+			"3.3.3.3,TL,Saudi Arabia,Gradymouth,-49.16,-86.05,3\n"+
+			"4.4.4.4,LI,Guyana,Port Karson,-78.22,-163.26,4\n"+
+			"5.5.5.5,PY,Paraguay,Asuncion,75.41,-144.69,5\n"), 0644)
+	assert.Nil(t, err)
+
+	mockStorer := datastore.NewInMemory()
+	mockCache := cache.NewInMemory()
+	mockCheckpointer := &lastRowCheckpointer{}
+
+	// batch with 3.3.3.3 fails, so position must stop right before it
+	ldr := geo.NewLoader(importer.NewCsvImporter(csvPath, 2), &ipFailingStorer{inMemoryStorer: mockStorer, ip: "3.3.3.3"}, mockCache)
+	ldr.Retry = &geo.RetryPolicy{Attempts: 1}
+	ldr.Checkpointer = mockCheckpointer
+
+	report := ldr.Load(context.Background(), 1)
+	assert.Equal(t, 3, report.Stored)
+	assert.NotNil(t, mockCheckpointer.last)
+	assert.Equal(t, 3, mockCheckpointer.last.Line)
+	// ips of failed batch are not cached, so resumed import doesn't discard them
+	assert.Len(t, mockCache.All(), 3)
+
+	// resume from the last committed position with the same cache
+	last := mockCheckpointer.last
+	ldr = geo.NewLoader(importer.NewCsvImporterFrom(csvPath, 2, last.Offset, last.Line), mockStorer, mockCache)
+	ldr.Checkpointer = mockCheckpointer
+
+	report = ldr.Load(context.Background(), 1)
+	assert.Equal(t, 3, report.Read)
+	assert.Equal(t, 2, report.Stored)
+	assert.Equal(t, 1, report.Duplicates)
+	assert.Equal(t, 6, mockCheckpointer.last.Line)
+
+	for _, ip := range []string{"1.1.1.1", "2.2.2.2", "3.3.3.3", "4.4.4.4", "5.5.5.5"} {
+		stored, err := mockStorer.ByIp(context.Background(), ip)
+		assert.Nil(t, err)
+		assert.NotNil(t, stored, ip)
+	}
+}
+
+// emptyBatchImporter sends empty batch before given *geo data
+type emptyBatchImporter struct {
+	given []*geo.Geo
+}
+
+func (imp *emptyBatchImporter) Import(context.Context) *geo.Imported {
+	imported := &geo.Imported{
+		GeoDataBatch: make(chan []*geo.Geo, 2),
+		OnError:      make(chan error),
+	}
+	imported.GeoDataBatch <- []*geo.Geo{}
+	imported.GeoDataBatch <- imp.given
+	close(imported.GeoDataBatch)
+	close(imported.OnError)
+
+	return imported
+}
+
+func TestLoader_Load_EmptyBatch(t *testing.T) {
+	mockCheckpointer := &lastRowCheckpointer{}
+
+	ldr := geo.NewLoader(&emptyBatchImporter{given: []*geo.Geo{{Ip: "1.1.1.1", Row: &geo.Row{Line: 2}}}},
+		datastore.NewInMemory(), cache.NewInMemory())
+	ldr.Checkpointer = mockCheckpointer
+
+	report := ldr.Load(context.Background(), 1)
+	assert.Equal(t, 1, report.Read)
+	assert.Equal(t, 1, report.Stored)
+	assert.Equal(t, 2, mockCheckpointer.last.Line)
+}
+
+func TestLoader_Warm(t *testing.T) {
+	mockStorer := datastore.NewInMemory()
+	_, err := mockStorer.Store(context.Background(), []*geo.Geo{{Ip: "1.1.1.1"}, {Ip: "2.2.2.2"}, {Ip: "3.3.3.3"}}, geo.ConflictSkip)
+	assert.Nil(t, err)
+
+	// cache was flushed, only one stored ip is left in it
+	mockCache := cache.NewInMemory()
+	assert.Nil(t, mockCache.Store(context.Background(), geo.CacheBucket{"2.2.2.2": "2.2.2.2"}))
+
+	ldr := geo.NewLoader(importer.NewInMemory([]*geo.Geo{{Ip: "1.1.1.1"}, {Ip: "4.4.4.4"}}, 2), mockStorer, mockCache)
+
+	diverged, err := ldr.Diverged(context.Background(), mockStorer, 2)
+	assert.Nil(t, err)
+	assert.True(t, diverged)
+
+	warmed, err := ldr.Warm(context.Background(), mockStorer, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, &geo.WarmReport{Scanned: 3, Missing: 2}, warmed)
+	assert.Len(t, mockCache.All(), 3)
+
+	diverged, err = ldr.Diverged(context.Background(), mockStorer, 2)
+	assert.Nil(t, err)
+	assert.False(t, diverged)
+
+	report := ldr.Load(context.Background(), 1)
+	assert.Equal(t, 1, report.Duplicates)
+	assert.Equal(t, 1, report.Stored)
+}
+
+func TestLoader_Load_DatastoreDedup(t *testing.T) {
+	mockStorer := datastore.NewInMemory()
+	_, err := mockStorer.Store(context.Background(), []*geo.Geo{{Ip: "1.1.1.1"}, {Ip: "2001:db8::1"}}, geo.ConflictSkip)
+	assert.Nil(t, err)
+
+	given := []*geo.Geo{{Ip: "1.1.1.1"}, {Ip: "2001:0db8::1"}, {Ip: "3.3.3.3"}}
+	ldr := geo.NewLoader(importer.NewInMemory(given, 3), mockStorer, cache.NewDatastore(mockStorer))
+
+	report := ldr.Load(context.Background(), 1)
+	assert.Equal(t, 2, report.Duplicates)
+	assert.Equal(t, 1, report.Stored)
+	assert.Len(t, mockStorer.All(), 3)
+}
+
+func TestLoader_Load_Progress(t *testing.T) {
+	row := &geo.Row{Fields: []string{"1.1.1.1", "NL", "Netherlands", "Amsterdam", "52.37", "4.89", "100"}}
+	given := []*geo.Geo{
+		{Ip: "1.1.1.1", Row: row},
+		{Ip: "2.2.2.2", Row: &geo.Row{Fields: []string{"2.2.2.2", "", "", "", "", "", ""}}},
+		{Ip: "2.2.2.2", Row: &geo.Row{Fields: []string{"2.2.2.2", "", "", "", "", "", ""}}},
+		{Ip: ""},
+	}
+
+	ldr := geo.NewLoader(importer.NewInMemory(given, 2), datastore.NewInMemory(), cache.NewInMemory())
+	ldr.ProgressInterval = time.Millisecond
+	ldr.Size = 100
+
+	var published []geo.Progress
+	ldr.Subscribe(func(p geo.Progress) {
+		published = append(published, p)
+	})
+	progress := ldr.ProgressChan(10)
+
+	report := ldr.Load(context.Background(), 2)
+
+	var last geo.Progress
+	for p := range progress {
+		last = p
+	}
+
+	assert.True(t, last.Done)
+	assert.Equal(t, report.RunId, last.RunId)
+	assert.Equal(t, 4, last.Read)
+	assert.Equal(t, 2, last.Filtered)
+	assert.Equal(t, 2, last.Stored)
+	assert.Equal(t, report.Invalid, last.Invalid)
+	assert.Equal(t, report.InFileDuplicates, last.InFileDuplicates)
+	assert.Equal(t, 1, last.Invalid)
+	assert.Equal(t, 1, last.InFileDuplicates)
+	// each row is estimated as its fields, separators and new line
+	assert.Equal(t, int64(48+14+14), last.Bytes)
+	assert.Equal(t, float64(100), last.Percent())
+	assert.Equal(t, time.Duration(0), last.Eta)
+
+	assert.NotEmpty(t, published)
+	assert.Equal(t, last, published[len(published)-1])
+}
+
+// progressFullStorer stores batches only once progress channel buffer is full
+type progressFullStorer struct {
+	inMemoryStorer
+	progress <-chan geo.Progress
+	buffer   int
+}
+
+func (s *progressFullStorer) Store(ctx context.Context, geoData []*geo.Geo, policy geo.ConflictPolicy) (int, error) {
+	for len(s.progress) < s.buffer {
+		time.Sleep(time.Millisecond)
+	}
+
+	return s.inMemoryStorer.Store(ctx, geoData, policy)
+}
+
+func TestLoader_Load_ProgressNotRead(t *testing.T) {
+	given := []*geo.Geo{{Ip: "1.1.1.1"}, {Ip: "2.2.2.2"}}
+
+	storer := &progressFullStorer{inMemoryStorer: datastore.NewInMemory(), buffer: 2}
+	ldr := geo.NewLoader(importer.NewInMemory(given, 1), storer, cache.NewInMemory())
+	ldr.ProgressInterval = time.Millisecond
+	storer.progress = ldr.ProgressChan(storer.buffer)
+
+	loaded := make(chan *geo.Report)
+	go func() {
+		loaded <- ldr.Load(context.Background(), 1)
+	}()
+
+	// nobody reads progress until Load is finished
+	var report *geo.Report
+	select {
+	case report = <-loaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("load is blocked by progress channel which is not read")
+	}
+
+	var published []geo.Progress
+	for p := range storer.progress {
+		published = append(published, p)
+	}
+
+	assert.Len(t, published, storer.buffer+1)
+	last := published[len(published)-1]
+	assert.True(t, last.Done)
+	assert.Equal(t, report.Stored, last.Stored)
+}
+
+func TestLoader_Load_InFileWindow(t *testing.T) {
+	// 1.1.1.1 is repeated within the window of 2 ips, 2.2.2.2 after it was moved out of the window
+	given := []*geo.Geo{{Ip: "1.1.1.1"}, {Ip: "2.2.2.2"}, {Ip: "1.1.1.1"}, {Ip: "3.3.3.3"}, {Ip: "4.4.4.4"}, {Ip: "2.2.2.2"}}
+
+	mockStorer := datastore.NewInMemory()
+	ldr := geo.NewLoader(importer.NewInMemory(given, 1), mockStorer, cache.NewInMemory())
+	ldr.InFileWindow = 2
+
+	report := ldr.Load(context.Background(), 1)
+
+	assert.Equal(t, 1, report.InFileDuplicates)
+	assert.Equal(t, 4, report.Stored)
+	assert.Len(t, mockStorer.All(), 4)
+	// whether the second 2.2.2.2 is found by Cache depends on when the first one is stored
+	assert.Equal(t, 1, report.Duplicates+report.Skipped)
+}
+
+// nopCache finds no duplicates and keeps nothing
+type nopCache struct{}
+
+func (nopCache) Store(context.Context, geo.CacheBucket) error {
+	return nil
+}
+
+func (nopCache) Get(context.Context, []string) (geo.KeySet, error) {
+	return make(geo.KeySet), nil
+}
+
+// heapStorer discards *geo data, and measures heap in use once given number of rows is stored
+type heapStorer struct {
+	stored  int
+	measure map[int]uint64
+}
+
+func (s *heapStorer) Store(_ context.Context, geoData []*geo.Geo, _ geo.ConflictPolicy) (int, error) {
+	s.stored += len(geoData)
+	if _, ok := s.measure[s.stored]; ok {
+		runtime.GC()
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		s.measure[s.stored] = stats.HeapAlloc
+	}
+
+	return len(geoData), nil
+}
+
+func TestLoader_Load_InFileWindowMemory(t *testing.T) {
+	rows := 400000
+	given := make([]*geo.Geo, 0, rows)
+	for i := 0; i < rows; i++ {
+		given = append(given, &geo.Geo{Ip: fmt.Sprintf("10.%d.%d.%d", i>>16&255, i>>8&255, i&255)})
+	}
+
+	// heap is measured while import is still running
+	mockStorer := &heapStorer{measure: map[int]uint64{rows / 4: 0, rows * 3 / 4: 0}}
+	ldr := geo.NewLoader(importer.NewInMemory(given, 100), mockStorer, nopCache{})
+	ldr.Retry = nil
+	ldr.InFileWindow = 1000
+
+	report := ldr.Load(context.Background(), 1)
+	assert.Equal(t, rows, report.Stored)
+	// imported rows are not garbage, so only memory held by loader is measured
+	runtime.KeepAlive(given)
+
+	// in-file duplicates are looked for only in the window, so memory doesn't grow with the number of rows
+	growth := int64(mockStorer.measure[rows*3/4]) - int64(mockStorer.measure[rows/4])
+	assert.Less(t, growth, int64(2<<20), "heap grew by %d bytes", growth)
+}
+
+func TestLoader_Load_InvalidIp(t *testing.T) {
+	given := []*geo.Geo{{Ip: "1.1.1.1"}, {Ip: "999.1.1.1"}, {Ip: "2.2.2.2"}}
+
+	mockStorer := datastore.NewInMemory()
+	ldr := geo.NewLoader(importer.NewInMemory(given, 3), mockStorer, cache.NewInMemory())
+	ldr.Validator = geo.NewValidator(0)
+
+	report := ldr.Load(context.Background(), 1)
+
+	// invalid ip doesn't reach data store, so it can't fail the rest of the batch
+	assert.Equal(t, 1, report.Invalid)
+	assert.Equal(t, 1, report.Rejected[geo.ReasonInvalidIp])
+	assert.Equal(t, 2, report.Stored)
+	assert.Equal(t, 0, report.Failed)
+	assert.Len(t, mockStorer.All(), 2)
+}
+
+// heartbeatStorer stores batches only once running run was saved again by heartbeat
+type heartbeatStorer struct {
+	inMemoryStorer
+	history   geo.RunSearch
+	heartbeat time.Duration
+}
+
+func (s *heartbeatStorer) Store(ctx context.Context, geoData []*geo.Geo, policy geo.ConflictPolicy) (int, error) {
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		runs, err := s.history.Runs(ctx, 0, 1)
+		if err != nil {
+			return 0, err
+		}
+		if len(runs) == 1 && runs[0].Status == geo.RunRunning && runs[0].UpdatedAt.Sub(runs[0].StartedAt) >= s.heartbeat {
+			return s.inMemoryStorer.Store(ctx, geoData, policy)
+		}
+	}
+
+	return 0, errors.New("running run is not saved by heartbeat")
+}
+
+func TestLoader_Load_Heartbeat(t *testing.T) {
+	history := datastore.NewInMemoryHistory()
+	storer := &heartbeatStorer{inMemoryStorer: datastore.NewInMemory(), history: history, heartbeat: 5 * time.Millisecond}
+
+	ldr := geo.NewLoader(importer.NewInMemory([]*geo.Geo{{Ip: "1.1.1.1"}}, 1), storer, cache.NewInMemory())
+	ldr.Retry = &geo.RetryPolicy{Attempts: 1}
+	ldr.History = history
+	ldr.Heartbeat = storer.heartbeat
+
+	report := ldr.Load(context.Background(), 1)
+	assert.Equal(t, 1, report.Stored)
+
+	run, err := history.RunById(context.Background(), report.RunId)
+	assert.Nil(t, err)
+	assert.Equal(t, geo.RunCompleted, run.Status)
+
+	// finished run is not stale
+	interrupted, err := history.InterruptStaleRuns(context.Background(), time.Now().Add(time.Hour))
+	assert.Nil(t, err)
+	assert.Zero(t, interrupted)
+}
@@ -0,0 +1,86 @@
+package geo
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// RunStatus is the state of a single Load run
+type RunStatus string
+
+const (
+	RunRunning   RunStatus = "running"
+	RunCompleted RunStatus = "completed"
+	// RunFailed means some *geo data failed to store
+	RunFailed    RunStatus = "failed"
+	RunCancelled RunStatus = "cancelled"
+	// RunInterrupted means loader stopped without finishing the run, e.g. it crashed
+	RunInterrupted RunStatus = "interrupted"
+)
+
+// defaultHeartbeat is how often running run is saved by default
+const defaultHeartbeat = time.Minute
+
+// Run presents a single Load run in import history
+type Run struct {
+	Id         string     `json:"id"`
+	Source     string     `json:"source"`
+	Checksum   string     `json:"checksum"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	Read       int        `json:"read"`
+	Accepted   int        `json:"accepted"`
+	Discarded  int        `json:"discarded"`
+	Status     RunStatus  `json:"status"`
+	// UpdatedAt is when run was saved the last time, running run is saved every heartbeat
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RunStore will persist import runs, saving existing run (by id) updates it
+type RunStore interface {
+	SaveRun(ctx context.Context, run *Run) error
+	// InterruptStaleRuns will mark running runs which were not saved since staleBefore as interrupted,
+	// their loader is gone. Finished at is the last time they were saved, number of marked runs is returned.
+	InterruptStaleRuns(ctx context.Context, staleBefore time.Time) (int, error)
+}
+
+// RunSearch will get import runs, ordered from the latest one
+type RunSearch interface {
+	// Runs returns at most limit runs, skipping the first offset ones
+	Runs(ctx context.Context, offset, limit int) ([]*Run, error)
+	RunById(ctx context.Context, id string) (*Run, error)
+}
+
+// newRun will create run with unique id, in running state
+func newRun(source, checksum string) *Run {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	return &Run{
+		Id:        hex.EncodeToString(id),
+		Source:    source,
+		Checksum:  checksum,
+		StartedAt: time.Now(),
+		Status:    RunRunning,
+	}
+}
+
+// finish will update run with final statistics
+func (run *Run) finish(report *Report, cancelled bool) {
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Read = report.Read
+	run.Accepted = report.Stored
+	run.Discarded = report.Discarded()
+
+	switch {
+	case cancelled:
+		run.Status = RunCancelled
+	case report.Failed > 0:
+		run.Status = RunFailed
+	default:
+		run.Status = RunCompleted
+	}
+}
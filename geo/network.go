@@ -0,0 +1,147 @@
+package geo
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// ParsePrefix will parse single ip or CIDR network, single ip is treated as /32 (or /128 for v6) network.
+// v4-mapped v6 addresses and networks are converted to v4.
+func ParsePrefix(s string) (netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		if p.Addr().Is4In6() && p.Bits() >= 96 {
+			p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+		}
+		return p.Masked(), nil
+	}
+
+	a, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	a = a.Unmap().WithZone("")
+
+	return netip.PrefixFrom(a, a.BitLen()), nil
+}
+
+// NormalizeIp returns canonical text form of ip or CIDR network, so that the same address always has the same key,
+// e.g. "2001:0db8:0000::1" is "2001:db8::1" and "::ffff:1.2.3.4" is "1.2.3.4"
+func NormalizeIp(s string) (string, error) {
+	p, err := ParsePrefix(s)
+	if err != nil {
+		return "", err
+	}
+
+	return Canonical(p), nil
+}
+
+// NormalizeLookupIp returns canonical text form of looked up ip, only single ip addresses (v4 or v6) are valid
+func NormalizeLookupIp(ip string) (string, bool) {
+	if _, err := netip.ParseAddr(ip); err != nil {
+		return "", false
+	}
+
+	normalized, err := NormalizeIp(ip)
+	if err != nil {
+		return "", false
+	}
+
+	return normalized, true
+}
+
+// Canonical returns canonical text form of network, single ip network is returned without mask
+func Canonical(p netip.Prefix) string {
+	if p.IsSingleIP() {
+		return p.Addr().String()
+	}
+
+	return p.String()
+}
+
+// ParseRange will parse "start-end" ip range and return minimal list of networks covering it
+func ParseRange(s string) ([]netip.Prefix, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid ip range: %s", s)
+	}
+
+	start, err := netip.ParseAddr(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return nil, err
+	}
+	end, err := netip.ParseAddr(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return nil, err
+	}
+
+	return RangeToPrefixes(start, end)
+}
+
+// RangeToPrefixes returns minimal list of networks covering all ips in [start, end]
+func RangeToPrefixes(start, end netip.Addr) ([]netip.Prefix, error) {
+	if start.BitLen() != end.BitLen() {
+		return nil, fmt.Errorf("ip range mixes v4 and v6: %s-%s", start, end)
+	}
+	if end.Less(start) {
+		return nil, fmt.Errorf("ip range end is before start: %s-%s", start, end)
+	}
+
+	var prefixes []netip.Prefix
+	for {
+		// the largest aligned network starting at start, which does not go past end
+		bits := start.BitLen()
+		for b := 0; b <= start.BitLen(); b++ {
+			p := netip.PrefixFrom(start, b)
+			if p.Masked().Addr() == start && !end.Less(lastAddr(p)) {
+				bits = b
+				break
+			}
+		}
+
+		p := netip.PrefixFrom(start, bits)
+		prefixes = append(prefixes, p)
+
+		last := lastAddr(p)
+		if last == end {
+			return prefixes, nil
+		}
+		start = last.Next()
+	}
+}
+
+// lastAddr returns the last address in network
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Masked().Addr().As16()
+	offset := 0
+	if p.Addr().Is4() {
+		offset = 96
+	}
+
+	for i := offset + p.Bits(); i < 128; i++ {
+		b[i/8] |= 1 << (7 - i%8)
+	}
+
+	a := netip.AddrFrom16(b)
+	if p.Addr().Is4() {
+		return a.Unmap()
+	}
+
+	return a
+}
+
+// Prefix returns network of *geo data, ip can be a single address or CIDR network
+func (g *Geo) Prefix() (netip.Prefix, bool) {
+	p, err := ParsePrefix(g.Ip)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+
+	return p, true
+}
@@ -0,0 +1,111 @@
+package geo_test
+
+import (
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseRange(t *testing.T) {
+	testTable := map[string]struct {
+		given       string
+		expected    []string
+		expectedErr bool
+	}{
+		"single ip": {
+			given:    "10.0.0.1-10.0.0.1",
+			expected: []string{"10.0.0.1/32"},
+		},
+		"aligned network": {
+			given:    "10.0.0.0-10.0.0.255",
+			expected: []string{"10.0.0.0/24"},
+		},
+		"unaligned range": {
+			given:    "10.0.0.1-10.0.0.6",
+			expected: []string{"10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/31", "10.0.0.6/32"},
+		},
+		"v6 range": {
+			given:    "2001:db8::-2001:db8::ffff",
+			expected: []string{"2001:db8::/112"},
+		},
+		"end before start": {
+			given:       "10.0.0.6-10.0.0.1",
+			expectedErr: true,
+		},
+		"mixed v4 and v6": {
+			given:       "10.0.0.1-2001:db8::1",
+			expectedErr: true,
+		},
+	}
+
+	for name, suite := range testTable {
+		t.Run(name, func(t *testing.T) {
+			prefixes, err := geo.ParseRange(suite.given)
+			if suite.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+
+			var networks []string
+			for _, p := range prefixes {
+				networks = append(networks, p.String())
+			}
+			assert.Equal(t, suite.expected, networks)
+		})
+	}
+}
+
+func TestNormalizeIp(t *testing.T) {
+	testTable := map[string]struct {
+		given       string
+		expected    string
+		expectedErr bool
+	}{
+		"v4":                       {given: "1.2.3.4", expected: "1.2.3.4"},
+		"v4-mapped v6":             {given: "::ffff:1.2.3.4", expected: "1.2.3.4"},
+		"v4-mapped v6 in hex form": {given: "::ffff:0102:0304", expected: "1.2.3.4"},
+		"v6 with leading zeros":    {given: "2001:0db8:0000::1", expected: "2001:db8::1"},
+		"v6 upper case":            {given: "2001:DB8::A", expected: "2001:db8::a"},
+		"v6 with zone":             {given: "fe80::1%eth0", expected: "fe80::1"},
+		"v4 network":               {given: "10.1.2.3/8", expected: "10.0.0.0/8"},
+		"v4-mapped v6 network":     {given: "::ffff:10.0.0.0/104", expected: "10.0.0.0/8"},
+		"v6 network":               {given: "2001:0db8::/32", expected: "2001:db8::/32"},
+		"invalid":                  {given: "999.1.1.1", expectedErr: true},
+	}
+
+	for name, suite := range testTable {
+		t.Run(name, func(t *testing.T) {
+			ip, err := geo.NormalizeIp(suite.given)
+			if suite.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, suite.expected, ip)
+		})
+	}
+}
+
+func TestNormalizeLookupIp(t *testing.T) {
+	testTable := map[string]struct {
+		given      string
+		expected   string
+		expectedOk bool
+	}{
+		"v4":           {given: "1.2.3.4", expected: "1.2.3.4", expectedOk: true},
+		"v4-mapped v6": {given: "::ffff:1.2.3.4", expected: "1.2.3.4", expectedOk: true},
+		"v6":           {given: "2001:0db8:0000::1", expected: "2001:db8::1", expectedOk: true},
+		"network":      {given: "10.0.0.0/8"},
+		"invalid":      {given: "999.1.1.1"},
+		"empty":        {given: ""},
+	}
+
+	for name, suite := range testTable {
+		t.Run(name, func(t *testing.T) {
+			ip, ok := geo.NormalizeLookupIp(suite.given)
+			assert.Equal(t, suite.expectedOk, ok)
+			assert.Equal(t, suite.expected, ip)
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package geo
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"time"
+)
+
+// defaultProgressInterval is how often Load progress is published by default
+const defaultProgressInterval = time.Second
+
+// Progress presents state of a running Load, it's published every ProgressInterval and once Load is finished
+type Progress struct {
+	RunId string `json:"run_id"`
+	// Read is number of rows read from Importer so far, including ones that failed to parse
+	Read int `json:"read"`
+	// Filtered is number of valid, not duplicate rows passed to Storer so far
+	Filtered int `json:"filtered"`
+	Stored   int `json:"stored"`
+	// ParseErrors, Invalid, InFileDuplicates, Duplicates, Skipped and Failed count rows by outcome so far, see Report
+	ParseErrors      int `json:"parse_errors"`
+	Invalid          int `json:"invalid"`
+	InFileDuplicates int `json:"in_file_duplicates"`
+	Duplicates       int `json:"duplicates"`
+	Skipped          int `json:"skipped"`
+	Failed           int `json:"failed"`
+	// Rps is number of rows read per second since previous progress
+	Rps     float64       `json:"rps"`
+	Elapsed time.Duration `json:"-"`
+	// Bytes is estimated number of source bytes read so far, out of Size (see loader Size)
+	Bytes int64 `json:"bytes"`
+	Size  int64 `json:"size"`
+	// Eta is estimated time left until Size bytes are read, it's 0 when Size is unknown
+	Eta time.Duration `json:"-"`
+	// Done is set on the last progress, once Load is finished
+	Done bool `json:"done"`
+}
+
+// Percent is share of Size bytes read so far (0-100), it's 0 when Size is unknown
+func (p Progress) Percent() float64 {
+	if p.Size <= 0 {
+		return 0
+	}
+	if p.Done || p.Bytes >= p.Size {
+		return 100
+	}
+
+	return float64(p.Bytes) / float64(p.Size) * 100
+}
+
+func (p Progress) MarshalJSON() ([]byte, error) {
+	type progress Progress
+
+	return json.Marshal(&struct {
+		progress
+		Elapsed string  `json:"elapsed"`
+		Eta     string  `json:"eta"`
+		Percent float64 `json:"percent"`
+	}{
+		progress: progress(p),
+		Elapsed:  p.Elapsed.String(),
+		Eta:      p.Eta.String(),
+		Percent:  p.Percent(),
+	})
+}
+
+// ProgressFunc receives Load progress. It's called from a single goroutine, so it should return quickly.
+type ProgressFunc func(Progress)
+
+// progress counts rows of a running Load, it's written by filter and storer goroutines
+type progress struct {
+	read             atomic.Int64
+	filtered         atomic.Int64
+	stored           atomic.Int64
+	bytes            atomic.Int64
+	parseErrors      atomic.Int64
+	invalid          atomic.Int64
+	inFileDuplicates atomic.Int64
+	duplicates       atomic.Int64
+	skipped          atomic.Int64
+	failed           atomic.Int64
+}
+
+// Subscribe will register fn to receive progress of each Load, it must be called before Load
+func (ldr *loader) Subscribe(fn ProgressFunc) {
+	ldr.subscribers = append(ldr.subscribers, fn)
+}
+
+// ProgressChan will subscribe to progress of the next Load. Progress is dropped when channel buffer is full,
+// except the last one, which has a slot of its own so that Load never waits for channel to be read.
+// Channel is closed once Load is finished.
+func (ldr *loader) ProgressChan(buffer int) <-chan Progress {
+	ch := make(chan Progress, buffer+1)
+	closed := false
+
+	ldr.Subscribe(func(p Progress) {
+		if closed {
+			return
+		}
+
+		if p.Done {
+			// doesn't block, the last slot is always free
+			ch <- p
+			close(ch)
+			closed = true
+			return
+		}
+
+		// subscriber is the only sender, so buffer can only get emptier after its length is checked
+		if len(ch) < buffer {
+			ch <- p
+		}
+	})
+
+	return ch
+}
+
+// publishProgress will publish progress of Load run to subscribers every ProgressInterval.
+// Returned func stops publishing and publishes the last progress.
+func (ldr *loader) publishProgress(runId string, prog *progress, startedAt time.Time) func() {
+	if len(ldr.subscribers) == 0 {
+		return func() {}
+	}
+
+	interval := ldr.ProgressInterval
+	if interval <= 0 {
+		interval = defaultProgressInterval
+	}
+
+	var prevRead int
+	prevAt := startedAt
+	snapshot := func(done bool) Progress {
+		now := time.Now()
+		p := Progress{
+			RunId:    runId,
+			Read:     int(prog.read.Load()),
+			Filtered: int(prog.filtered.Load()),
+			Stored:   int(prog.stored.Load()),
+			Elapsed:  now.Sub(startedAt),
+			Bytes:    prog.bytes.Load(),
+			Size:     ldr.Size,
+			Done:     done,
+
+			ParseErrors:      int(prog.parseErrors.Load()),
+			Invalid:          int(prog.invalid.Load()),
+			InFileDuplicates: int(prog.inFileDuplicates.Load()),
+			Duplicates:       int(prog.duplicates.Load()),
+			Skipped:          int(prog.skipped.Load()),
+			Failed:           int(prog.failed.Load()),
+		}
+
+		if dt := now.Sub(prevAt).Seconds(); dt > 0 {
+			p.Rps = float64(p.Read-prevRead) / dt
+		}
+		prevRead, prevAt = p.Read, now
+
+		// eta assumes the rest of the source is read as fast as it was so far
+		if !done && p.Bytes > 0 && p.Size > p.Bytes {
+			p.Eta = time.Duration(float64(p.Elapsed) * float64(p.Size-p.Bytes) / float64(p.Bytes)).Round(time.Second)
+		}
+
+		return p
+	}
+
+	publish := func(p Progress) {
+		for _, fn := range ldr.subscribers {
+			fn(p)
+		}
+	}
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				publish(snapshot(false))
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-stopped
+		publish(snapshot(true))
+	}
+}
+
+// rowBytes estimates number of source bytes of given rows, each row is counted once
+// even if it was expanded into many *geo data
+func rowBytes(batch []*Geo) int64 {
+	var n int64
+	var prev *Row
+	for _, g := range batch {
+		if g.Row == nil || g.Row == prev {
+			continue
+		}
+		prev = g.Row
+		n += fieldsBytes(g.Row.Fields)
+	}
+
+	return n
+}
+
+// fieldsBytes estimates length of csv line with given fields, including separators and new line
+func fieldsBytes(fields []string) int64 {
+	n := int64(len(fields))
+	for _, f := range fields {
+		n += int64(len(f))
+	}
+
+	return n
+}
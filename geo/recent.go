@@ -0,0 +1,58 @@
+package geo
+
+// defaultInFileWindow is number of the most recently accepted ips checked for in-file duplicates by default
+const defaultInFileWindow = 100000
+
+// recentIps keeps ips accepted from the most recent batches. It holds at most size ips plus ips of the current batch,
+// so its memory use doesn't grow with the source.
+type recentIps struct {
+	size int
+	// ips are kept with sequence of the batch they were accepted in
+	ips map[string]int
+	// batches are accepted ips of each batch in the window, ordered from the oldest one with sequence first
+	batches [][]string
+	first   int
+	current []string
+	count   int
+}
+
+func newRecentIps(size int) *recentIps {
+	if size <= 0 {
+		size = defaultInFileWindow
+	}
+
+	return &recentIps{
+		size: size,
+		ips:  make(map[string]int),
+	}
+}
+
+func (r *recentIps) has(ip string) bool {
+	_, ok := r.ips[ip]
+	return ok
+}
+
+// accept will add ip to the current batch
+func (r *recentIps) accept(ip string) {
+	r.ips[ip] = r.first + len(r.batches)
+	r.current = append(r.current, ip)
+}
+
+// next will finish the current batch, and forget the oldest batches which don't fit in the window anymore
+func (r *recentIps) next() {
+	r.batches = append(r.batches, r.current)
+	r.count += len(r.current)
+	r.current = nil
+
+	for r.count > r.size && len(r.batches) > 1 {
+		for _, ip := range r.batches[0] {
+			if r.ips[ip] == r.first {
+				delete(r.ips, ip)
+			}
+		}
+		r.count -= len(r.batches[0])
+		r.batches[0] = nil
+		r.batches = r.batches[1:]
+		r.first++
+	}
+}
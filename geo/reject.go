@@ -0,0 +1,94 @@
+package geo
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// RejectReason categorizes why imported row was discarded
+type RejectReason string
+
+const (
+	ReasonMalformedCsv    RejectReason = "malformed_csv"
+	ReasonBadLatitude     RejectReason = "bad_latitude"
+	ReasonBadLongitude    RejectReason = "bad_longitude"
+	ReasonBadMysteryValue RejectReason = "bad_mystery_value"
+	ReasonEmptyIp         RejectReason = "empty_ip"
+	ReasonInvalidIp       RejectReason = "invalid_ip"
+	ReasonBadCountryCode  RejectReason = "bad_country_code"
+	ReasonCountryMismatch RejectReason = "country_mismatch"
+	ReasonDuplicateInFile RejectReason = "duplicate_in_file"
+	ReasonDuplicateStored RejectReason = "duplicate_stored"
+)
+
+// Row describes where *geo data was read from
+type Row struct {
+	Line int
+	// Offset is byte offset right after the row in its source
+	Offset int64
+	Fields []string
+}
+
+// Rejection presents single discarded row, with its line number, raw fields and the reason.
+// Importers should send it on Imported.OnError, so the reason is not lost.
+type Rejection struct {
+	Line   int          `json:"line"`
+	Fields []string     `json:"fields"`
+	Reason RejectReason `json:"reason"`
+	Err    error        `json:"-"`
+}
+
+// Rejecter will receive every discarded row
+type Rejecter interface {
+	Reject(*Rejection) error
+}
+
+func (r *Rejection) Error() string {
+	if r.Err != nil {
+		return fmt.Sprintf("line %d: %s: %v", r.Line, r.Reason, r.Err)
+	}
+
+	return fmt.Sprintf("line %d: %s", r.Line, r.Reason)
+}
+
+func (r *Rejection) Unwrap() error {
+	return r.Err
+}
+
+// Fields returns raw fields *geo data was imported from, or its formatted values if raw are unknown
+func (g *Geo) Fields() []string {
+	if g.Row != nil && len(g.Row.Fields) > 0 {
+		return g.Row.Fields
+	}
+
+	return []string{
+		g.Ip,
+		g.CountryCode,
+		g.Country,
+		g.City,
+		strconv.FormatFloat(g.Latitude, 'f', -1, 64),
+		strconv.FormatFloat(g.Longitude, 'f', -1, 64),
+		strconv.Itoa(g.MysteryValue),
+	}
+}
+
+// reject creates *Rejection for given *geo data
+func (g *Geo) reject(reason RejectReason) *Rejection {
+	r := &Rejection{
+		Fields: g.Fields(),
+		Reason: reason,
+	}
+	if g.Row != nil {
+		r.Line = g.Row.Line
+	}
+
+	return r
+}
+
+// rejectWith creates *Rejection for given *geo data, with the error explaining it
+func (g *Geo) rejectWith(reason RejectReason, err error) *Rejection {
+	r := g.reject(reason)
+	r.Err = err
+
+	return r
+}
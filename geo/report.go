@@ -0,0 +1,77 @@
+package geo
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Report presents statistics of a single Load run
+type Report struct {
+	RunId   string        `json:"run_id"`
+	Elapsed time.Duration `json:"-"`
+	// Read is total number of rows read from Importer, including ones that failed to parse
+	Read        int `json:"read"`
+	ParseErrors int `json:"parse_errors"`
+	Invalid     int `json:"invalid"`
+	// InFileDuplicates repeat an ip accepted in this run, in the same batch or within loader InFileWindow
+	InFileDuplicates int `json:"in_file_duplicates"`
+	// Duplicates were already persisted before this run, as found by Cache
+	Duplicates int `json:"duplicates"`
+	Stored     int `json:"stored"`
+	// Skipped were accepted by Storer without error but not stored, e.g. ip conflicts resolved in data store
+	Skipped int `json:"skipped"`
+	Failed  int `json:"failed"`
+	// Rejected counts discarded rows by reason
+	Rejected map[RejectReason]int `json:"rejected"`
+	Workers  []*WorkerReport      `json:"workers"`
+}
+
+// WorkerReport presents statistics of a single Storer worker
+type WorkerReport struct {
+	Worker   int `json:"worker"`
+	Received int `json:"received"`
+	Stored   int `json:"stored"`
+	Skipped  int `json:"skipped"`
+	Failed   int `json:"failed"`
+}
+
+// Discarded is number of rows which were not stored, for whatever reason
+func (r *Report) Discarded() int {
+	return r.ParseErrors + r.Invalid + r.InFileDuplicates + r.Duplicates + r.Skipped + r.Failed
+}
+
+// DiscardRate is ratio of discarded rows to all read rows
+func (r *Report) DiscardRate() float64 {
+	if r.Read == 0 {
+		return 0
+	}
+
+	return float64(r.Discarded()) / float64(r.Read)
+}
+
+// Rps is number of read rows per second
+func (r *Report) Rps() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+
+	return float64(r.Read) / r.Elapsed.Seconds()
+}
+
+func (r *Report) MarshalJSON() ([]byte, error) {
+	type report Report
+
+	return json.Marshal(&struct {
+		*report
+		Elapsed     string  `json:"elapsed"`
+		Discarded   int     `json:"discarded"`
+		DiscardRate float64 `json:"discard_rate"`
+		Rps         float64 `json:"rps"`
+	}{
+		report:      (*report)(r),
+		Elapsed:     r.Elapsed.String(),
+		Discarded:   r.Discarded(),
+		DiscardRate: r.DiscardRate(),
+		Rps:         r.Rps(),
+	})
+}
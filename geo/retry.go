@@ -0,0 +1,144 @@
+package geo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy defines how failed Storer.Store calls are re-tried.
+// Delay between attempts grows exponentially from Backoff up to MaxBackoff,
+// and is randomized by Jitter (fraction of the delay, 0-1).
+type RetryPolicy struct {
+	Attempts   int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	Jitter     float64
+	// OnRetry (optional) is called before each re-try attempt (starting from 1) with error of the previous one,
+	// once backoff delay is over
+	OnRetry func(attempt int, err error)
+}
+
+// DeadLetter will receive *geo data batches that could not be stored even after all re-tries,
+// so they can be replayed later
+type DeadLetter interface {
+	Store([]*Geo, error) error
+}
+
+// PartialError is returned by Storer when only some of *geo data batch is stored, e.g. one of its chunks failed.
+// Returned int is number of stored *geo data. Any other error, except *ConflictError, means nothing is stored.
+type PartialError struct {
+	// Ips are not stored ips, as they were given, they can be re-tried
+	Ips []string
+	Err error
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("%d ips not stored: %v", len(e.Ips), e.Err)
+}
+
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
+
+// unstored returns *geo data of batch which is not stored
+func (e *PartialError) unstored(batch []*Geo) []*Geo {
+	ips := make(map[string]struct{}, len(e.Ips))
+	for _, ip := range e.Ips {
+		ips[ip] = struct{}{}
+	}
+
+	unstored := make([]*Geo, 0, len(e.Ips))
+	for _, g := range batch {
+		if _, ok := ips[g.Ip]; ok {
+			unstored = append(unstored, g)
+		}
+	}
+
+	return unstored
+}
+
+// NewRetryPolicy will initialize *RetryPolicy with default values
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		Attempts:   3,
+		Backoff:    100 * time.Millisecond,
+		MaxBackoff: 5 * time.Second,
+		Jitter:     0.2,
+	}
+}
+
+// delay returns how long to wait before given re-try attempt (starting from 1)
+func (rp *RetryPolicy) delay(attempt int) time.Duration {
+	d := rp.Backoff
+	for i := 1; i < attempt && d < math.MaxInt64/2; i++ {
+		// MaxBackoff 0 means there is no cap
+		if rp.MaxBackoff > 0 && d >= rp.MaxBackoff {
+			break
+		}
+		d *= 2
+	}
+	if rp.MaxBackoff > 0 && d > rp.MaxBackoff {
+		d = rp.MaxBackoff
+	}
+
+	if rp.Jitter > 0 {
+		j := time.Duration(rp.Jitter * float64(d))
+		if j > 0 {
+			d = d - j + time.Duration(rand.Int63n(int64(2*j)))
+		}
+	}
+
+	return d
+}
+
+// storeWithRetry will try to store *geo data batch until it succeeds, attempts are exhausted or ctx is done.
+// Only *geo data which is not stored yet is re-tried. Returned are number of stored *geo data,
+// and *geo data which is still not stored when it fails.
+func (ldr *loader) storeWithRetry(ctx context.Context, batch []*Geo) (int, []*Geo, error) {
+	attempts := 1
+	if ldr.Retry != nil && ldr.Retry.Attempts > 1 {
+		attempts = ldr.Retry.Attempts
+	}
+
+	pending := batch
+	var stored int
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return stored, pending, err
+			case <-time.After(ldr.Retry.delay(attempt)):
+			}
+			// attempt abandoned while waiting is not a re-try
+			if ldr.Retry.OnRetry != nil {
+				ldr.Retry.OnRetry(attempt, err)
+			}
+		}
+
+		var n int
+		n, err = ldr.storer.Store(ctx, pending, ldr.Conflict)
+		if err == nil {
+			return stored + n, nil, nil
+		}
+		// conflicts will not go away on re-try
+		if errors.Is(err, ErrConflict) {
+			return stored + n, nil, err
+		}
+
+		var partial *PartialError
+		if errors.As(err, &partial) {
+			stored += n
+			pending = partial.unstored(pending)
+			if len(pending) == 0 {
+				return stored, nil, nil
+			}
+		}
+	}
+
+	return stored, pending, err
+}
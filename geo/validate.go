@@ -0,0 +1,142 @@
+package geo
+
+import (
+	"fmt"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Rule is a single *geo data validation rule, rules are combined with |
+type Rule uint
+
+const (
+	// RuleIp requires valid v4 or v6 ip address or CIDR network, ip is normalized to its canonical form.
+	// Data store requires valid ips, so it's always enabled.
+	RuleIp Rule = 1 << iota
+	// RuleCoordinates requires latitude in [-90, 90] and longitude in [-180, 180]
+	RuleCoordinates
+	// RuleCountryCode requires ISO 3166-1 alpha-2 country code
+	RuleCountryCode
+	// RuleCountryConsistency requires country name to match country code
+	RuleCountryConsistency
+	// RuleMysteryValue requires non-negative mystery value
+	RuleMysteryValue
+
+	RuleAll = RuleIp | RuleCoordinates | RuleCountryCode | RuleCountryConsistency | RuleMysteryValue
+)
+
+var ruleNames = map[string]Rule{
+	"ip":                  RuleIp,
+	"coordinates":         RuleCoordinates,
+	"country_code":        RuleCountryCode,
+	"country_consistency": RuleCountryConsistency,
+	"mystery_value":       RuleMysteryValue,
+	"all":                 RuleAll,
+}
+
+// Validator will validate *geo data against enabled rules.
+// Valid ip is always required (RuleIp), regardless of rules.
+type Validator struct {
+	Rules Rule
+}
+
+// NewValidator will initialize *Validator with given rules enabled
+func NewValidator(rules Rule) *Validator {
+	return &Validator{
+		Rules: rules,
+	}
+}
+
+// ParseRules will parse comma separated rule names, e.g. "ip,coordinates"
+func ParseRules(names string) (Rule, error) {
+	var rules Rule
+
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		r, ok := ruleNames[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown validation rule: %s", name)
+		}
+		rules |= r
+	}
+
+	return rules, nil
+}
+
+// Validate will check *geo data against enabled rules, returned *Rejection is nil for valid *geo data.
+// *Geo data ip is normalized.
+func (v *Validator) Validate(g *Geo) *Rejection {
+	if strings.TrimSpace(g.Ip) == "" {
+		return g.reject(ReasonEmptyIp)
+	}
+
+	ip, err := NormalizeIp(g.Ip)
+	if err != nil {
+		return g.rejectWith(ReasonInvalidIp, fmt.Errorf("invalid ip address: %s", g.Ip))
+	}
+	g.Ip = ip
+
+	if v.enabled(RuleCoordinates) {
+		if !validCoordinate(g.Latitude, 90) {
+			return g.rejectWith(ReasonBadLatitude, fmt.Errorf("latitude out of range: %v", g.Latitude))
+		}
+		if !validCoordinate(g.Longitude, 180) {
+			return g.rejectWith(ReasonBadLongitude, fmt.Errorf("longitude out of range: %v", g.Longitude))
+		}
+	}
+
+	if v.enabled(RuleCountryCode) || v.enabled(RuleCountryConsistency) {
+		name, ok := countries[strings.ToUpper(g.CountryCode)]
+		if !ok {
+			return g.rejectWith(ReasonBadCountryCode, fmt.Errorf("unknown country code: %s", g.CountryCode))
+		}
+
+		if v.enabled(RuleCountryConsistency) && !sameCountry(name, g.Country) {
+			return g.rejectWith(ReasonCountryMismatch, fmt.Errorf("country %s does not match code %s", g.Country, g.CountryCode))
+		}
+	}
+
+	if v.enabled(RuleMysteryValue) && g.MysteryValue < 0 {
+		return g.rejectWith(ReasonBadMysteryValue, fmt.Errorf("negative mystery value: %d", g.MysteryValue))
+	}
+
+	return nil
+}
+
+func (v *Validator) enabled(rule Rule) bool {
+	return v.Rules&rule != 0
+}
+
+// sameCountry compares country names ignoring case, diacritics, punctuation and whitespace,
+// e.g. "Côte d'Ivoire" is the same as "Cote d'Ivoire"
+func sameCountry(a, b string) bool {
+	normalize := func(s string) string {
+		// letters are decomposed so that diacritics (nonspacing marks) can be removed from them
+		folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn))), s)
+		if err != nil {
+			folded = s
+		}
+
+		return strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+			return -1
+		}, strings.ToLower(folded))
+	}
+
+	return normalize(a) == normalize(b)
+}
+
+// validCoordinate checks if coordinate is a number within [-max, max], NaN and infinity are not valid
+func validCoordinate(c, max float64) bool {
+	return !math.IsNaN(c) && !math.IsInf(c, 0) && c >= -max && c <= max
+}
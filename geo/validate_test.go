@@ -0,0 +1,122 @@
+package geo_test
+
+import (
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestValidator_Validate(t *testing.T) {
+	testTable := map[string]struct {
+		rules          geo.Rule
+		given          *geo.Geo
+		expectedReason geo.RejectReason
+		expectedIp     string
+	}{
+		"blank ip is always invalid": {
+			rules:          0,
+			given:          &geo.Geo{Ip: " "},
+			expectedReason: geo.ReasonEmptyIp,
+		},
+		"bogus ip is invalid without ip rule": {
+			rules:          0,
+			given:          &geo.Geo{Ip: "999.1.1.1"},
+			expectedReason: geo.ReasonInvalidIp,
+		},
+		"ip is normalized without ip rule": {
+			rules:      0,
+			given:      &geo.Geo{Ip: "2001:0DB8::1"},
+			expectedIp: "2001:db8::1",
+		},
+		"bogus ip is invalid": {
+			rules:          geo.RuleIp,
+			given:          &geo.Geo{Ip: "999.1.1.1"},
+			expectedReason: geo.ReasonInvalidIp,
+		},
+		"ipv6 is normalized": {
+			rules:      geo.RuleIp,
+			given:      &geo.Geo{Ip: "2001:0db8:0000::1"},
+			expectedIp: "2001:db8::1",
+		},
+		"latitude out of range": {
+			rules:          geo.RuleCoordinates,
+			given:          &geo.Geo{Ip: "1.1.1.1", Latitude: -184},
+			expectedReason: geo.ReasonBadLatitude,
+		},
+		"longitude out of range": {
+			rules:          geo.RuleCoordinates,
+			given:          &geo.Geo{Ip: "1.1.1.1", Longitude: 180.1},
+			expectedReason: geo.ReasonBadLongitude,
+		},
+		"latitude is not a number": {
+			rules:          geo.RuleCoordinates,
+			given:          &geo.Geo{Ip: "1.1.1.1", Latitude: math.NaN()},
+			expectedReason: geo.ReasonBadLatitude,
+		},
+		"longitude is infinite": {
+			rules:          geo.RuleCoordinates,
+			given:          &geo.Geo{Ip: "1.1.1.1", Longitude: math.Inf(1)},
+			expectedReason: geo.ReasonBadLongitude,
+		},
+		"unknown country code": {
+			rules:          geo.RuleCountryCode,
+			given:          &geo.Geo{Ip: "1.1.1.1", CountryCode: "XX1"},
+			expectedReason: geo.ReasonBadCountryCode,
+		},
+		"country does not match code": {
+			rules:          geo.RuleCountryConsistency,
+			given:          &geo.Geo{Ip: "1.1.1.1", CountryCode: "SI", Country: "Nepal"},
+			expectedReason: geo.ReasonCountryMismatch,
+		},
+		"country matches code": {
+			rules:      geo.RuleAll,
+			given:      &geo.Geo{Ip: "1.1.1.1", CountryCode: "fk", Country: "falkland islands malvinas"},
+			expectedIp: "1.1.1.1",
+		},
+		"country with diacritics matches code": {
+			rules:      geo.RuleCountryConsistency,
+			given:      &geo.Geo{Ip: "1.1.1.1", CountryCode: "CW", Country: "Curaçao"},
+			expectedIp: "1.1.1.1",
+		},
+		"country with diacritics and apostrophe matches code": {
+			rules:      geo.RuleCountryConsistency,
+			given:      &geo.Geo{Ip: "1.1.1.1", CountryCode: "CI", Country: "Côte d'Ivoire"},
+			expectedIp: "1.1.1.1",
+		},
+		"country with diacritics does not match other code": {
+			rules:          geo.RuleCountryConsistency,
+			given:          &geo.Geo{Ip: "1.1.1.1", CountryCode: "CU", Country: "Curaçao"},
+			expectedReason: geo.ReasonCountryMismatch,
+		},
+		"negative mystery value": {
+			rules:          geo.RuleMysteryValue,
+			given:          &geo.Geo{Ip: "1.1.1.1", MysteryValue: -1},
+			expectedReason: geo.ReasonBadMysteryValue,
+		},
+	}
+
+	for name, suite := range testTable {
+		t.Run(name, func(t *testing.T) {
+			rejection := geo.NewValidator(suite.rules).Validate(suite.given)
+
+			if suite.expectedReason == "" {
+				assert.Nil(t, rejection)
+				assert.Equal(t, suite.expectedIp, suite.given.Ip)
+				return
+			}
+
+			assert.NotNil(t, rejection)
+			assert.Equal(t, suite.expectedReason, rejection.Reason)
+		})
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := geo.ParseRules("ip, coordinates,mystery_value")
+	assert.Nil(t, err)
+	assert.Equal(t, geo.RuleIp|geo.RuleCoordinates|geo.RuleMysteryValue, rules)
+
+	_, err = geo.ParseRules("ip,bogus")
+	assert.NotNil(t, err)
+}
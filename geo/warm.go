@@ -0,0 +1,93 @@
+package geo
+
+import (
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+)
+
+// IpScanner will scan all stored ips in batches, scanning stops when fn returns error
+type IpScanner interface {
+	ScanIps(ctx context.Context, batchSize int, fn func(ips []string) error) error
+}
+
+// WarmReport holds statistics about Cache warm up
+type WarmReport struct {
+	// Scanned is number of stored ips
+	Scanned int `json:"scanned"`
+	// Missing is number of stored ips which were not in Cache, they are stored in it now
+	Missing int `json:"missing"`
+}
+
+// Warm will store in Cache every ip stored in data store which Cache is missing,
+// e.g. after Cache was flushed while data store still holds *geo data
+func (ldr *loader) Warm(ctx context.Context, scanner IpScanner, batchSize int) (*WarmReport, error) {
+	report := &WarmReport{}
+
+	err := scanner.ScanIps(ctx, batchSize, func(ips []string) error {
+		missing, err := ldr.missingInCache(ctx, ips)
+		if err != nil {
+			return err
+		}
+
+		report.Scanned += len(ips)
+		report.Missing += len(missing)
+
+		if len(missing) == 0 {
+			return nil
+		}
+
+		bucket := make(CacheBucket)
+		for _, ip := range missing {
+			bucket[ip] = ip
+		}
+		return ldr.cache.Store(ctx, bucket)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("cache warmed up, %d of %d stored ips were missing", report.Missing, report.Scanned)
+
+	return report, nil
+}
+
+// Diverged will check if Cache is missing any of the first sample stored ips
+func (ldr *loader) Diverged(ctx context.Context, scanner IpScanner, sample int) (bool, error) {
+	diverged := false
+
+	err := scanner.ScanIps(ctx, sample, func(ips []string) error {
+		missing, err := ldr.missingInCache(ctx, ips)
+		if err != nil {
+			return err
+		}
+
+		diverged = len(missing) > 0
+		// only the first batch is checked
+		return errSampled
+	})
+	if err != nil && !errors.Is(err, errSampled) {
+		return false, err
+	}
+
+	return diverged, nil
+}
+
+// errSampled stops scanning once sample is checked
+var errSampled = errors.New("sampled")
+
+func (ldr *loader) missingInCache(ctx context.Context, ips []string) ([]string, error) {
+	existing, err := ldr.cache.Get(ctx, ips)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := make([]string, 0)
+	for _, ip := range ips {
+		if !existing.Has(ip) {
+			missing = append(missing, ip)
+		}
+	}
+
+	return missing, nil
+}
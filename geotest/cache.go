@@ -0,0 +1,97 @@
+package geotest
+
+import (
+	"context"
+	"fmt"
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+// TestCache will run conformance suite against geo.Cache, newCache must return empty cache
+func TestCache(t *testing.T, newCache func(t *testing.T) geo.Cache) {
+	t.Run("empty cache has no keys", func(t *testing.T) {
+		c := newCache(t)
+
+		existing, err := c.Get(context.Background(), []string{"1.1.1.1", "2.2.2.2"})
+		assert.Nil(t, err)
+		assert.NotNil(t, existing)
+		assert.Empty(t, existing)
+	})
+
+	t.Run("only stored keys exist", func(t *testing.T) {
+		c := newCache(t)
+
+		assert.Nil(t, c.Store(context.Background(), geo.CacheBucket{"1.1.1.1": "1.1.1.1", "2001:db8::1": "2001:db8::1"}))
+
+		existing, err := c.Get(context.Background(), []string{"1.1.1.1", "2.2.2.2", "2001:db8::1"})
+		assert.Nil(t, err)
+		assert.Equal(t, geo.KeySet{"1.1.1.1": {}, "2001:db8::1": {}}, existing)
+	})
+
+	t.Run("only requested keys are returned", func(t *testing.T) {
+		c := newCache(t)
+
+		assert.Nil(t, c.Store(context.Background(), geo.CacheBucket{"1.1.1.1": "1.1.1.1", "2.2.2.2": "2.2.2.2"}))
+
+		existing, err := c.Get(context.Background(), []string{"2.2.2.2"})
+		assert.Nil(t, err)
+		assert.Equal(t, geo.KeySet{"2.2.2.2": {}}, existing)
+	})
+
+	t.Run("storing duplicates", func(t *testing.T) {
+		c := newCache(t)
+
+		assert.Nil(t, c.Store(context.Background(), geo.CacheBucket{"1.1.1.1": "1.1.1.1"}))
+		assert.Nil(t, c.Store(context.Background(), geo.CacheBucket{"1.1.1.1": "1.1.1.1"}))
+
+		existing, err := c.Get(context.Background(), []string{"1.1.1.1", "1.1.1.1"})
+		assert.Nil(t, err)
+		assert.Equal(t, geo.KeySet{"1.1.1.1": {}}, existing)
+	})
+
+	t.Run("empty bucket and keys", func(t *testing.T) {
+		c := newCache(t)
+
+		assert.Nil(t, c.Store(context.Background(), geo.CacheBucket{}))
+
+		existing, err := c.Get(context.Background(), []string{})
+		assert.Nil(t, err)
+		assert.Empty(t, existing)
+	})
+
+	t.Run("concurrent store and get", func(t *testing.T) {
+		c := newCache(t)
+
+		var wg sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < 50; i++ {
+					ip := fmt.Sprintf("10.0.%d.%d", w, i)
+					assert.Nil(t, c.Store(context.Background(), geo.CacheBucket{ip: ip}))
+
+					existing, err := c.Get(context.Background(), []string{ip})
+					assert.Nil(t, err)
+					assert.True(t, existing.Has(ip))
+				}
+			}(w)
+		}
+		wg.Wait()
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		c := newCache(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		assert.Error(t, c.Store(ctx, geo.CacheBucket{"1.1.1.1": "1.1.1.1"}))
+
+		existing, err := c.Get(ctx, []string{"1.1.1.1"})
+		assert.Error(t, err)
+		assert.Nil(t, existing)
+	})
+}
@@ -0,0 +1,35 @@
+// Package geotest provides conformance suites for geo.Storer, geo.Search, geo.Cache and geo.Importer implementations.
+// Each suite is run from a regular test, e.g.
+//
+//	func TestMyStorer(t *testing.T) {
+//		geotest.TestStorer(t, func(t *testing.T) geo.Storer {
+//			return NewMyStorer()
+//		})
+//	}
+package geotest
+
+import (
+	"github.com/semirm-dev/findhotel/geo"
+	"time"
+)
+
+// timeout is how long suites wait for asynchronous results, e.g. importer channels to close
+const timeout = 5 * time.Second
+
+// sample returns *geo data with all fields set, each ip is unique
+func sample(ips ...string) []*geo.Geo {
+	geoData := make([]*geo.Geo, 0, len(ips))
+	for i, ip := range ips {
+		geoData = append(geoData, &geo.Geo{
+			Ip:           ip,
+			CountryCode:  "NL",
+			Country:      "Netherlands",
+			City:         "Amsterdam",
+			Latitude:     52.37 + float64(i),
+			Longitude:    4.89 + float64(i),
+			MysteryValue: 100 + i,
+		})
+	}
+
+	return geoData
+}
@@ -0,0 +1,121 @@
+package geotest
+
+import (
+	"context"
+	"fmt"
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// TestImporter will run conformance suite against geo.Importer,
+// newImporter must return importer of given *geo data which sends batches of at most batchSize
+func TestImporter(t *testing.T, newImporter func(t *testing.T, given []*geo.Geo, batchSize int) geo.Importer) {
+	t.Run("imports all geo data in batches", func(t *testing.T) {
+		given := sample("1.1.1.1", "2.2.2.2", "3.3.3.3", "2001:db8::1", "10.0.0.0/8")
+		imp := newImporter(t, given, 2)
+
+		batches, errs := drain(t, imp.Import(context.Background()))
+		assert.Empty(t, errs)
+
+		imported := make(map[string]*geo.Geo)
+		for _, batch := range batches {
+			assert.NotEmpty(t, batch)
+			assert.LessOrEqual(t, len(batch), 2)
+			for _, g := range batch {
+				imported[g.Ip] = g
+			}
+		}
+
+		assert.Len(t, imported, len(given))
+		for _, g := range given {
+			if assert.Contains(t, imported, g.Ip) {
+				assert.Equal(t, g.CountryCode, imported[g.Ip].CountryCode)
+				assert.Equal(t, g.Country, imported[g.Ip].Country)
+				assert.Equal(t, g.City, imported[g.Ip].City)
+				assert.InDelta(t, g.Latitude, imported[g.Ip].Latitude, 0.000001)
+				assert.InDelta(t, g.Longitude, imported[g.Ip].Longitude, 0.000001)
+				assert.Equal(t, g.MysteryValue, imported[g.Ip].MysteryValue)
+			}
+		}
+	})
+
+	t.Run("empty source", func(t *testing.T) {
+		imp := newImporter(t, []*geo.Geo{}, 2)
+
+		batches, errs := drain(t, imp.Import(context.Background()))
+		assert.Empty(t, batches)
+		assert.Empty(t, errs)
+	})
+
+	t.Run("cancelled import is finished", func(t *testing.T) {
+		given := make([]string, 0)
+		for i := 0; i < 100; i++ {
+			given = append(given, fmt.Sprintf("10.0.0.%d", i))
+		}
+		imp := newImporter(t, sample(given...), 1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		imported := imp.Import(ctx)
+
+		first := <-imported.GeoDataBatch
+		cancel()
+
+		// imported channels must be closed, batches which were in flight when import was cancelled can still arrive
+		batches, _ := drain(t, imported)
+		batches = append(batches, first)
+
+		ips := make(map[string]bool)
+		for _, batch := range batches {
+			for _, g := range batch {
+				assert.False(t, ips[g.Ip], "%s is imported more than once", g.Ip)
+				ips[g.Ip] = true
+			}
+		}
+		for ip := range ips {
+			assert.Contains(t, given, ip)
+		}
+	})
+}
+
+// TestImporterErrors will check if errors are sent on geo.Imported OnError,
+// newBroken must return importer whose source has expected number of rows that can't be imported
+func TestImporterErrors(t *testing.T, newBroken func(t *testing.T) (imp geo.Importer, expected int)) {
+	imp, expected := newBroken(t)
+
+	_, errs := drain(t, imp.Import(context.Background()))
+	assert.Len(t, errs, expected)
+	for _, err := range errs {
+		assert.Error(t, err)
+	}
+}
+
+// drain will read everything imported, until both channels are closed
+func drain(t *testing.T, imported *geo.Imported) ([][]*geo.Geo, []error) {
+	var batches [][]*geo.Geo
+	var errs []error
+
+	deadline := time.After(timeout)
+	geoDataBatch, onError := imported.GeoDataBatch, imported.OnError
+	for geoDataBatch != nil || onError != nil {
+		select {
+		case batch, ok := <-geoDataBatch:
+			if !ok {
+				geoDataBatch = nil
+				continue
+			}
+			batches = append(batches, batch)
+		case err, ok := <-onError:
+			if !ok {
+				onError = nil
+				continue
+			}
+			errs = append(errs, err)
+		case <-deadline:
+			t.Fatal("importer channels are not closed")
+		}
+	}
+
+	return batches, errs
+}
@@ -0,0 +1,81 @@
+package geotest
+
+import (
+	"context"
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// TestSearch will run conformance suite against geo.Search, newSearch must return search over given *geo data only
+func TestSearch(t *testing.T, newSearch func(t *testing.T, stored []*geo.Geo) geo.Search) {
+	stored := sample("1.1.1.1", "2001:db8::1", "10.0.0.0/8", "10.1.0.0/16")
+
+	t.Run("finds stored ip", func(t *testing.T) {
+		s := newSearch(t, stored)
+
+		g, err := s.ByIp(context.Background(), "1.1.1.1")
+		assert.Nil(t, err)
+		if assert.NotNil(t, g) {
+			assert.Equal(t, "1.1.1.1", g.Ip)
+			assert.Equal(t, stored[0].CountryCode, g.CountryCode)
+			assert.Equal(t, stored[0].City, g.City)
+			assert.Equal(t, stored[0].MysteryValue, g.MysteryValue)
+		}
+	})
+
+	t.Run("not found ip is nil without error", func(t *testing.T) {
+		s := newSearch(t, stored)
+
+		g, err := s.ByIp(context.Background(), "5.5.5.5")
+		assert.Nil(t, err)
+		assert.Nil(t, g)
+	})
+
+	t.Run("finds the most specific network", func(t *testing.T) {
+		s := newSearch(t, stored)
+
+		g, err := s.ByIp(context.Background(), "10.1.2.3")
+		assert.Nil(t, err)
+		if assert.NotNil(t, g) {
+			assert.Equal(t, "10.1.0.0/16", g.Ip)
+			assert.Equal(t, "10.1.0.0/16", g.Network)
+		}
+	})
+
+	t.Run("finds many ips keyed by given ip", func(t *testing.T) {
+		s := newSearch(t, stored)
+
+		found, err := s.ByIps(context.Background(), []string{"1.1.1.1", "2001:db8::1", "10.2.2.2", "5.5.5.5", "1.1.1.1"})
+		assert.Nil(t, err)
+		assert.Len(t, found, 3)
+		assert.Equal(t, "1.1.1.1", found["1.1.1.1"].Ip)
+		assert.Equal(t, "2001:db8::1", found["2001:db8::1"].Ip)
+		assert.Equal(t, "10.0.0.0/8", found["10.2.2.2"].Ip)
+		assert.NotContains(t, found, "5.5.5.5")
+	})
+
+	t.Run("no ips", func(t *testing.T) {
+		s := newSearch(t, stored)
+
+		found, err := s.ByIps(context.Background(), []string{})
+		assert.Nil(t, err)
+		assert.NotNil(t, found)
+		assert.Empty(t, found)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		s := newSearch(t, stored)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		g, err := s.ByIp(ctx, "1.1.1.1")
+		assert.Error(t, err)
+		assert.Nil(t, g)
+
+		found, err := s.ByIps(ctx, []string{"1.1.1.1"})
+		assert.Error(t, err)
+		assert.Nil(t, found)
+	})
+}
@@ -0,0 +1,121 @@
+package geotest
+
+import (
+	"context"
+	"errors"
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// TestStorer will run conformance suite against geo.Storer, newStorer must return empty data store
+func TestStorer(t *testing.T, newStorer func(t *testing.T) geo.Storer) {
+	t.Run("stores new geo data", func(t *testing.T) {
+		s := newStorer(t)
+
+		stored, err := s.Store(context.Background(), sample("1.1.1.1", "2.2.2.2", "2001:db8::1"), geo.ConflictSkip)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, stored)
+	})
+
+	t.Run("given geo data is not changed", func(t *testing.T) {
+		s := newStorer(t)
+
+		given := sample("1.1.1.1", "10.0.0.0/24")
+		expected := make([]geo.Geo, len(given))
+		for i, g := range given {
+			expected[i] = *g
+		}
+
+		_, err := s.Store(context.Background(), given, geo.ConflictSkip)
+		assert.Nil(t, err)
+		_, err = s.Store(context.Background(), given, geo.ConflictOverwrite)
+		assert.Nil(t, err)
+
+		for i, g := range given {
+			assert.Equal(t, expected[i], *g)
+		}
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		s := newStorer(t)
+
+		stored, err := s.Store(context.Background(), []*geo.Geo{}, geo.ConflictSkip)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, stored)
+	})
+
+	t.Run("duplicates are skipped", func(t *testing.T) {
+		s := newStorer(t)
+
+		_, err := s.Store(context.Background(), sample("1.1.1.1"), geo.ConflictSkip)
+		assert.Nil(t, err)
+
+		stored, err := s.Store(context.Background(), sample("1.1.1.1", "2.2.2.2"), geo.ConflictSkip)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, stored)
+	})
+
+	t.Run("duplicates are overwritten", func(t *testing.T) {
+		s := newStorer(t)
+
+		_, err := s.Store(context.Background(), sample("1.1.1.1"), geo.ConflictSkip)
+		assert.Nil(t, err)
+
+		stored, err := s.Store(context.Background(), sample("1.1.1.1", "2.2.2.2"), geo.ConflictOverwrite)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, stored)
+	})
+
+	t.Run("duplicates are overwritten only with newer geo data", func(t *testing.T) {
+		s := newStorer(t)
+
+		observedAt := time.Now().UTC().Truncate(time.Second)
+
+		old := sample("1.1.1.1")
+		old[0].ObservedAt = observedAt
+		_, err := s.Store(context.Background(), old, geo.ConflictSkip)
+		assert.Nil(t, err)
+
+		older := sample("1.1.1.1")
+		older[0].ObservedAt = observedAt.Add(-time.Hour)
+		stored, err := s.Store(context.Background(), older, geo.ConflictOverwriteIfNewer)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, stored)
+
+		newer := sample("1.1.1.1")
+		newer[0].ObservedAt = observedAt.Add(time.Hour)
+		stored, err = s.Store(context.Background(), newer, geo.ConflictOverwriteIfNewer)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, stored)
+	})
+
+	t.Run("duplicates fail with ErrConflict", func(t *testing.T) {
+		s := newStorer(t)
+
+		_, err := s.Store(context.Background(), sample("1.1.1.1"), geo.ConflictSkip)
+		assert.Nil(t, err)
+
+		stored, err := s.Store(context.Background(), sample("1.1.1.1", "2.2.2.2"), geo.ConflictFail)
+		assert.True(t, errors.Is(err, geo.ErrConflict), "expected ErrConflict, got %v", err)
+
+		// ips which are not stored yet are stored anyway
+		var conflict *geo.ConflictError
+		if assert.True(t, errors.As(err, &conflict), "expected *ConflictError, got %v", err) {
+			assert.Equal(t, []string{"1.1.1.1"}, conflict.Ips)
+		}
+		assert.Equal(t, 1, stored)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		s := newStorer(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		stored, err := s.Store(ctx, sample("1.1.1.1"), geo.ConflictSkip)
+		assert.Error(t, err)
+		assert.Equal(t, 0, stored)
+	})
+}
@@ -0,0 +1,151 @@
+package importer
+
+import (
+	"context"
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"sync"
+)
+
+type chunkedCsvImporter struct {
+	path      string
+	batchSize int
+	chunks    int
+}
+
+// chunk is byte range [start, end) of csv file, aligned on record boundaries
+type chunk struct {
+	start int64
+	end   int64
+	// lineOffset is number of lines before chunk start
+	lineOffset int
+}
+
+// NewChunkedCsvImporter will split csv file into byte-range chunks and parse each chunk concurrently.
+// Chunks are aligned on csv record boundaries, so quoted fields may contain new lines.
+func NewChunkedCsvImporter(path string, batchSize, chunks int) geo.Importer {
+	if chunks < 1 {
+		chunks = 1
+	}
+
+	return &chunkedCsvImporter{
+		path:      path,
+		batchSize: batchSize,
+		chunks:    chunks,
+	}
+}
+
+func (imp *chunkedCsvImporter) Import(ctx context.Context) *geo.Imported {
+	imported := &geo.Imported{
+		GeoDataBatch: make(chan []*geo.Geo),
+		OnError:      make(chan error),
+	}
+
+	go func(ctx context.Context, imported *geo.Imported) {
+		defer func() {
+			close(imported.GeoDataBatch)
+			close(imported.OnError)
+			logrus.Warn("chunked csv importer finished")
+		}()
+
+		csvFile, csvErr := os.Open(imp.path)
+		if csvErr != nil {
+			sendError(ctx, imported, csvErr)
+			return
+		}
+		defer func() {
+			if err := csvFile.Close(); err != nil {
+				logrus.Error(err)
+				return
+			}
+			logrus.Warn("csv file closed")
+		}()
+
+		chunks, err := splitIntoChunks(csvFile, imp.chunks)
+		if err != nil {
+			sendError(ctx, imported, err)
+			return
+		}
+
+		observedAt := modTime(csvFile)
+
+		var wg sync.WaitGroup
+		wg.Add(len(chunks))
+		for i, c := range chunks {
+			go func(i int, c *chunk) {
+				defer wg.Done()
+
+				readCsv(ctx, &section{
+					r:          io.NewSectionReader(csvFile, c.start, c.end-c.start),
+					byteOffset: c.start,
+					lineOffset: c.lineOffset,
+					// only the first chunk starts with csv header
+					skipHeader: i == 0,
+				}, imp.batchSize, observedAt, imported)
+			}(i, c)
+		}
+		wg.Wait()
+	}(ctx, imported)
+
+	return imported
+}
+
+// splitIntoChunks will split file into n chunks of similar size, each chunk ends right after a record.
+// File is scanned once, new lines within quoted fields are not record boundaries, but they are counted in lineOffset
+// as csv reader counts them too.
+func splitIntoChunks(f *os.File, n int) ([]*chunk, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+
+	// target returns offset where i-th chunk should start at the earliest
+	target := func(i int) int64 {
+		return size * int64(i) / int64(n)
+	}
+
+	chunks := []*chunk{{}}
+	next := 1
+	r := io.NewSectionReader(f, 0, size)
+	buf := make([]byte, 64*1024)
+	var pos int64
+	lines := 0
+	quoted := false
+
+	for {
+		k, err := r.Read(buf)
+		for _, b := range buf[:k] {
+			pos++
+
+			switch b {
+			case '"':
+				// escaped quote "" toggles twice, so state is kept
+				quoted = !quoted
+			case '\n':
+				lines++
+				if quoted || next >= n || pos < target(next) || pos >= size {
+					continue
+				}
+
+				chunks[len(chunks)-1].end = pos
+				chunks = append(chunks, &chunk{start: pos, lineOffset: lines})
+				for next < n && target(next) <= pos {
+					next++
+				}
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	chunks[len(chunks)-1].end = size
+
+	return chunks, nil
+}
@@ -3,16 +3,33 @@ package importer
 import (
 	"context"
 	"encoding/csv"
+	"errors"
+	"fmt"
 	"github.com/semirm-dev/findhotel/geo"
 	"github.com/sirupsen/logrus"
 	"io"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type csvImporter struct {
 	path      string
 	batchSize int
+	// offset and line where import starts from, when resuming previous import
+	offset int64
+	line   int
+}
+
+// section is part of csv file read by a single reader
+type section struct {
+	r io.Reader
+	// byteOffset and lineOffset are number of bytes and lines preceding r in the file,
+	// so reported positions are absolute
+	byteOffset int64
+	lineOffset int
+	skipHeader bool
 }
 
 func NewCsvImporter(path string, batchSize int) geo.Importer {
@@ -22,6 +39,17 @@ func NewCsvImporter(path string, batchSize int) geo.Importer {
 	}
 }
 
+// NewCsvImporterFrom will initialize csv importer which continues from given byte offset and line,
+// usually taken from last committed geo.Row. Header is not expected in that case.
+func NewCsvImporterFrom(path string, batchSize int, offset int64, line int) geo.Importer {
+	return &csvImporter{
+		path:      path,
+		batchSize: batchSize,
+		offset:    offset,
+		line:      line,
+	}
+}
+
 func (imp *csvImporter) Import(ctx context.Context) *geo.Imported {
 	imported := &geo.Imported{
 		GeoDataBatch: make(chan []*geo.Geo),
@@ -37,7 +65,8 @@ func (imp *csvImporter) Import(ctx context.Context) *geo.Imported {
 
 		csvFile, csvErr := os.Open(imp.path)
 		if csvErr != nil {
-			logrus.Fatal(csvErr)
+			sendError(ctx, imported, csvErr)
+			return
 		}
 		defer func() {
 			if err := csvFile.Close(); err != nil {
@@ -47,67 +76,129 @@ func (imp *csvImporter) Import(ctx context.Context) *geo.Imported {
 			logrus.Warn("csv file closed")
 		}()
 
-		csvr := csv.NewReader(csvFile)
-		buf := make([]*geo.Geo, 0, imp.batchSize)
-		first := true
-
-		for {
-			select {
-			case <-ctx.Done():
+		if imp.offset > 0 {
+			if _, err := csvFile.Seek(imp.offset, io.SeekStart); err != nil {
+				sendError(ctx, imported, err)
 				return
-			default:
-				row, err := csvr.Read()
-				if err != nil {
-					if err == io.EOF {
-						// check for leftover, incomplete buf
-						if len(buf) > 0 {
-							imported.GeoDataBatch <- buf
-						}
-						return
+			}
+			logrus.Infof("csv importer resumed from line %d", imp.line)
+		}
+
+		readCsv(ctx, &section{
+			r:          csvFile,
+			byteOffset: imp.offset,
+			lineOffset: imp.line,
+			skipHeader: imp.offset == 0,
+		}, imp.batchSize, modTime(csvFile), imported)
+	}(ctx, imported)
+
+	return imported
+}
+
+// readCsv will read csv rows from section and send them to imported in batches.
+// observedAt is set on each *geo data, usually it's csv file modification time.
+func readCsv(ctx context.Context, sec *section, batchSize int, observedAt time.Time, imported *geo.Imported) {
+	csvr := csv.NewReader(sec.r)
+	// number of columns is checked when encoding, a chunk might not start with a header
+	csvr.FieldsPerRecord = -1
+	buf := make([]*geo.Geo, 0, batchSize)
+	first := sec.skipHeader
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			row, err := csvr.Read()
+			if err != nil {
+				if err == io.EOF {
+					// check for leftover, incomplete buf
+					if len(buf) > 0 {
+						sendBatch(ctx, imported, buf)
 					}
-					imported.OnError <- err
-					continue
+					return
 				}
+				sendError(ctx, imported, malformed(sec.lineOffset, row, err))
+				continue
+			}
 
-				// skip first record, it's csv header
-				if first {
-					first = false
-					continue
-				}
+			// skip first record, it's csv header
+			if first {
+				first = false
+				continue
+			}
 
-				geoData, err := encodeToGeo(row)
-				if err != nil {
-					imported.OnError <- err
-					continue
-				}
+			line, _ := csvr.FieldPos(0)
+			geoData, err := encodeToGeo(sec.lineOffset+line, row)
+			if err != nil {
+				sendError(ctx, imported, err)
+				continue
+			}
 
-				buf = append(buf, geoData)
+			// ip range rows are expanded into one *geo data per network, ips are normalized
+			networks, err := expandRange(geoData)
+			if err != nil {
+				sendError(ctx, imported, err)
+				continue
+			}
 
-				if len(buf) >= imp.batchSize {
-					imported.GeoDataBatch <- buf
-					buf = nil // reset buf
+			geoData.Row.Offset = sec.byteOffset + csvr.InputOffset()
+			for _, g := range networks {
+				g.ObservedAt = observedAt
+				buf = append(buf, g)
+			}
+
+			if len(buf) >= batchSize {
+				if !sendBatch(ctx, imported, buf) {
+					return
 				}
+				buf = make([]*geo.Geo, 0, batchSize) // reset buf
 			}
 		}
-	}(ctx, imported)
+	}
+}
 
-	return imported
+func sendBatch(ctx context.Context, imported *geo.Imported, batch []*geo.Geo) bool {
+	select {
+	case imported.GeoDataBatch <- batch:
+		return true
+	case <-ctx.Done():
+		return false
+	}
 }
 
-func encodeToGeo(row []string) (*geo.Geo, error) {
+func sendError(ctx context.Context, imported *geo.Imported, err error) bool {
+	select {
+	case imported.OnError <- err:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// columns is number of columns each csv row must have
+const columns = 7
+
+// encodeToGeo will convert csv row to *geo data, returned error is always *geo.Rejection
+func encodeToGeo(line int, row []string) (*geo.Geo, error) {
+	if len(row) != columns {
+		return nil, &geo.Rejection{Line: line, Fields: row, Reason: geo.ReasonMalformedCsv,
+			Err: fmt.Errorf("expected %d columns, got %d", columns, len(row))}
+	}
+
 	ip, ccode, country, city, lat, long, myst := row[0], row[1], row[2], row[3], row[4], row[5], row[6]
 
 	latitude, err := strconv.ParseFloat(lat, 64)
 	if err != nil && lat != "" {
-		return nil, err
+		return nil, &geo.Rejection{Line: line, Fields: row, Reason: geo.ReasonBadLatitude, Err: err}
 	}
 	longitude, err := strconv.ParseFloat(long, 64)
 	if err != nil && long != "" {
-		return nil, err
+		return nil, &geo.Rejection{Line: line, Fields: row, Reason: geo.ReasonBadLongitude, Err: err}
 	}
 	mystVal, err := strconv.Atoi(myst)
 	if err != nil && myst != "" {
-		return nil, err
+		return nil, &geo.Rejection{Line: line, Fields: row, Reason: geo.ReasonBadMysteryValue, Err: err}
 	}
 
 	return &geo.Geo{
@@ -118,5 +209,60 @@ func encodeToGeo(row []string) (*geo.Geo, error) {
 		Latitude:     latitude,
 		Longitude:    longitude,
 		MysteryValue: mystVal,
+		Row: &geo.Row{
+			Line:   line,
+			Fields: row,
+		},
 	}, nil
 }
+
+// expandRange will split *geo data with "start-end" ip range into *geo data for each covering CIDR network.
+// Single ips and CIDR networks are normalized, invalid ones are left to geo.Validator.
+func expandRange(geoData *geo.Geo) ([]*geo.Geo, error) {
+	if !strings.Contains(geoData.Ip, "-") {
+		if ip, err := geo.NormalizeIp(geoData.Ip); err == nil {
+			geoData.Ip = ip
+		}
+		return []*geo.Geo{geoData}, nil
+	}
+
+	prefixes, err := geo.ParseRange(geoData.Ip)
+	if err != nil {
+		return nil, &geo.Rejection{Line: geoData.Row.Line, Fields: geoData.Row.Fields, Reason: geo.ReasonInvalidIp, Err: err}
+	}
+
+	networks := make([]*geo.Geo, 0, len(prefixes))
+	for _, p := range prefixes {
+		g := *geoData
+		g.Ip = geo.Canonical(p)
+		networks = append(networks, &g)
+	}
+
+	return networks, nil
+}
+
+// modTime returns file modification time, or zero time if unknown
+func modTime(f *os.File) time.Time {
+	info, err := f.Stat()
+	if err != nil {
+		return time.Time{}
+	}
+
+	return info.ModTime()
+}
+
+// malformed will wrap csv reader error as *geo.Rejection
+func malformed(lineOffset int, row []string, err error) *geo.Rejection {
+	rejection := &geo.Rejection{
+		Fields: row,
+		Reason: geo.ReasonMalformedCsv,
+		Err:    err,
+	}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		rejection.Line = lineOffset + parseErr.StartLine
+	}
+
+	return rejection
+}
@@ -0,0 +1,164 @@
+package importer_test
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/semirm-dev/findhotel/geotest"
+	"github.com/semirm-dev/findhotel/importer"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInMemory_Conformance(t *testing.T) {
+	geotest.TestImporter(t, func(t *testing.T, given []*geo.Geo, batchSize int) geo.Importer {
+		return importer.NewInMemory(given, batchSize)
+	})
+}
+
+func TestCsvImporter_Conformance(t *testing.T) {
+	geotest.TestImporter(t, func(t *testing.T, given []*geo.Geo, batchSize int) geo.Importer {
+		return importer.NewCsvImporter(writeCsv(t, given), batchSize)
+	})
+
+	t.Run("malformed rows", func(t *testing.T) {
+		geotest.TestImporterErrors(t, func(t *testing.T) (geo.Importer, int) {
+			path := filepath.Join(t.TempDir(), "data_dump.csv")
+			assert.Nil(t, os.WriteFile(path, []byte(
+				"ip_address,country_code,country,city,latitude,longitude,mystery_value\n"+
+					"1.1.1.1,NL,Netherlands,Amsterdam,52.37,4.89,100\n"+
+					"2.2.2.2,NL,Netherlands,Amsterdam,north,4.89,100\n"+
+					"3.3.3.3,NL,Netherlands\n"+
+					"4.4.4.9-4.4.4.1,NL,Netherlands,Amsterdam,52.37,4.89,100\n"), 0644))
+
+			return importer.NewCsvImporter(path, 2), 3
+		})
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		geotest.TestImporterErrors(t, func(t *testing.T) (geo.Importer, int) {
+			return importer.NewCsvImporter(filepath.Join(t.TempDir(), "missing.csv"), 2), 1
+		})
+	})
+}
+
+func TestChunkedCsvImporter_Conformance(t *testing.T) {
+	geotest.TestImporter(t, func(t *testing.T, given []*geo.Geo, batchSize int) geo.Importer {
+		return importer.NewChunkedCsvImporter(writeCsv(t, given), batchSize, 3)
+	})
+}
+
+func TestChunkedCsvImporter_Lines(t *testing.T) {
+	given := make([]*geo.Geo, 100)
+	expected := make(map[string]int)
+	for i := range given {
+		ip := fmt.Sprintf("10.0.%d.%d", i/256, i%256)
+		given[i] = &geo.Geo{Ip: ip, CountryCode: "NL", Country: "Netherlands", City: "Amsterdam"}
+		// header is the first line
+		expected[ip] = i + 2
+	}
+	path := writeCsv(t, given)
+
+	for _, chunks := range []int{1, 3, 7, 16} {
+		t.Run(fmt.Sprintf("%d chunks", chunks), func(t *testing.T) {
+			lines, errs := importLines(t, importer.NewChunkedCsvImporter(path, 10, chunks))
+
+			assert.Empty(t, errs)
+			assert.Equal(t, expected, lines)
+		})
+	}
+}
+
+func TestChunkedCsvImporter_QuotedNewLines(t *testing.T) {
+	given := make([]*geo.Geo, 100)
+	expected := make(map[string]int)
+	line := 2
+	for i := range given {
+		ip := fmt.Sprintf("10.0.%d.%d", i/256, i%256)
+		city := "Amsterdam"
+		if i%2 == 0 {
+			city = "Den\nHaag\nZuid"
+		}
+		given[i] = &geo.Geo{Ip: ip, CountryCode: "NL", Country: "Netherlands", City: city}
+		expected[ip] = line
+		line += strings.Count(city, "\n") + 1
+	}
+	path := writeCsv(t, given)
+
+	sequential, errs := importLines(t, importer.NewCsvImporter(path, 10))
+	assert.Empty(t, errs)
+	assert.Equal(t, expected, sequential)
+
+	for _, chunks := range []int{3, 7, 16} {
+		t.Run(fmt.Sprintf("%d chunks", chunks), func(t *testing.T) {
+			lines, errs := importLines(t, importer.NewChunkedCsvImporter(path, 10, chunks))
+
+			assert.Empty(t, errs)
+			assert.Equal(t, expected, lines)
+		})
+	}
+}
+
+// importLines will import everything and return csv line of each imported ip
+func importLines(t *testing.T, imp geo.Importer) (map[string]int, []error) {
+	lines := make(map[string]int)
+	var errs []error
+
+	imported := imp.Import(context.Background())
+	geoDataBatch, onError := imported.GeoDataBatch, imported.OnError
+	for geoDataBatch != nil || onError != nil {
+		select {
+		case batch, ok := <-geoDataBatch:
+			if !ok {
+				geoDataBatch = nil
+				continue
+			}
+			for _, g := range batch {
+				lines[g.Ip] = g.Row.Line
+			}
+		case err, ok := <-onError:
+			if !ok {
+				onError = nil
+				continue
+			}
+			errs = append(errs, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("importer channels are not closed")
+		}
+	}
+
+	return lines, errs
+}
+
+// writeCsv will write *geo data to temporary csv file with header, in data dump format
+func writeCsv(t *testing.T, geoData []*geo.Geo) string {
+	path := filepath.Join(t.TempDir(), "data_dump.csv")
+
+	f, err := os.Create(path)
+	assert.Nil(t, err)
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	assert.Nil(t, w.Write([]string{"ip_address", "country_code", "country", "city", "latitude", "longitude", "mystery_value"}))
+	for _, g := range geoData {
+		assert.Nil(t, w.Write([]string{
+			g.Ip,
+			g.CountryCode,
+			g.Country,
+			g.City,
+			fmt.Sprint(g.Latitude),
+			fmt.Sprint(g.Longitude),
+			strconv.Itoa(g.MysteryValue),
+		}))
+	}
+	w.Flush()
+	assert.Nil(t, w.Error())
+
+	return path
+}
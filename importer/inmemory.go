@@ -28,7 +28,7 @@ func (imp inmemory) Import(ctx context.Context) *geo.Imported {
 
 		defer func() {
 			if len(buf) > 0 {
-				imported.GeoDataBatch <- buf
+				sendBatch(ctx, imported, buf)
 			}
 
 			close(imported.GeoDataBatch)
@@ -43,7 +43,9 @@ func (imp inmemory) Import(ctx context.Context) *geo.Imported {
 				buf = append(buf, b)
 
 				if len(buf) >= imp.batchSize {
-					imported.GeoDataBatch <- buf
+					if !sendBatch(ctx, imported, buf) {
+						return
+					}
 					buf = nil // reset buf
 				}
 			}
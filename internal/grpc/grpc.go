@@ -13,14 +13,13 @@ type ServiceRegistrar interface {
 	RegisterGrpcServer(server *grpc.Server)
 }
 
-// ListenForConnections will start grpc server and start listening for connections
-func ListenForConnections(ctx context.Context, registrar ServiceRegistrar, addr, serviceName string) {
+// ListenForConnections will start grpc server and start listening for connections, opts are applied to grpc server
+func ListenForConnections(ctx context.Context, registrar ServiceRegistrar, addr, serviceName string, opts ...grpc.ServerOption) {
 	lis, err := net.Listen("tcp", addr)
 	if err != nil {
 		logrus.Fatal(err)
 	}
 
-	var opts []grpc.ServerOption
 	srv := grpc.NewServer(opts...)
 
 	registrar.RegisterGrpcServer(srv)
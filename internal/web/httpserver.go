@@ -26,7 +26,7 @@ func ServeHttp(addr, serviceName string, router http.Handler) {
 		}
 	}()
 
-	quit := make(chan os.Signal)
+	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
@@ -0,0 +1,179 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/semirm-dev/findhotel/proto/geopb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type importService struct {
+	geopb.UnimplementedImportServiceServer
+	manager *manager
+}
+
+// NewImportService will initialize grpc ImportService, imports are run by manager
+func NewImportService(manager *manager) *importService {
+	return &importService{
+		manager: manager,
+	}
+}
+
+func (svc *importService) RegisterGrpcServer(server *grpc.Server) {
+	geopb.RegisterImportServiceServer(server, svc)
+}
+
+func (svc *importService) StartImport(_ context.Context, req *geopb.StartImportRequest) (*geopb.ImportStatus, error) {
+	j, err := svc.manager.Start(req.GetPath(), int(req.GetWorkers()))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return importStatus(j), nil
+}
+
+func (svc *importService) GetStatus(ctx context.Context, req *geopb.GetStatusRequest) (*geopb.ImportStatus, error) {
+	j, err := svc.manager.Status(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return importStatus(j), nil
+}
+
+func (svc *importService) Cancel(ctx context.Context, req *geopb.CancelRequest) (*geopb.ImportStatus, error) {
+	if err := svc.manager.Cancel(req.GetId()); err != nil {
+		return nil, toStatusError(err)
+	}
+
+	done, err := svc.manager.Done(req.GetId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+
+	j, err := svc.manager.Status(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return importStatus(j), nil
+}
+
+func (svc *importService) StreamProgress(req *geopb.StreamProgressRequest, stream geopb.ImportService_StreamProgressServer) error {
+	for {
+		j, changed, err := svc.manager.Watch(req.GetId())
+		if err != nil {
+			return toStatusError(err)
+		}
+
+		if err = stream.Send(importStatus(j)); err != nil {
+			return err
+		}
+
+		if j.Finished() {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+	}
+}
+
+// toStatusError will convert manager error to grpc status error
+func toStatusError(err error) error {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrInvalidPath):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, ErrRunning):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+var importStates = map[geo.RunStatus]geopb.ImportState{
+	geo.RunRunning:   geopb.ImportState_IMPORT_STATE_RUNNING,
+	geo.RunCompleted: geopb.ImportState_IMPORT_STATE_COMPLETED,
+	geo.RunFailed:    geopb.ImportState_IMPORT_STATE_FAILED,
+	geo.RunCancelled: geopb.ImportState_IMPORT_STATE_CANCELLED,
+	// only run found in History can be interrupted
+	geo.RunInterrupted: geopb.ImportState_IMPORT_STATE_INTERRUPTED,
+}
+
+func importStatus(j *Job) *geopb.ImportStatus {
+	s := &geopb.ImportStatus{
+		Id:        j.Id,
+		Path:      j.Path,
+		State:     importStates[j.Status],
+		StartedAt: timestamppb.New(j.StartedAt),
+		Error:     j.Error,
+	}
+
+	if j.FinishedAt != nil {
+		s.FinishedAt = timestamppb.New(*j.FinishedAt)
+	}
+
+	if j.Progress != nil {
+		s.Progress = importProgress(j.Progress)
+	}
+
+	if j.Report != nil {
+		s.Report = importReport(j.Report)
+	}
+
+	return s
+}
+
+func importReport(report *geo.Report) *geopb.ImportReport {
+	rejected := make(map[string]int64, len(report.Rejected))
+	for reason, total := range report.Rejected {
+		rejected[string(reason)] = int64(total)
+	}
+
+	return &geopb.ImportReport{
+		RunId:            report.RunId,
+		Elapsed:          durationpb.New(report.Elapsed),
+		Read:             int64(report.Read),
+		ParseErrors:      int64(report.ParseErrors),
+		Invalid:          int64(report.Invalid),
+		InFileDuplicates: int64(report.InFileDuplicates),
+		Duplicates:       int64(report.Duplicates),
+		Stored:           int64(report.Stored),
+		Skipped:          int64(report.Skipped),
+		Failed:           int64(report.Failed),
+		Rejected:         rejected,
+		DiscardRate:      report.DiscardRate(),
+		Rps:              report.Rps(),
+	}
+}
+
+func importProgress(p *geo.Progress) *geopb.ImportProgress {
+	return &geopb.ImportProgress{
+		Read:     int64(p.Read),
+		Filtered: int64(p.Filtered),
+		Stored:   int64(p.Stored),
+		Rps:      p.Rps,
+		Elapsed:  durationpb.New(p.Elapsed),
+		Bytes:    p.Bytes,
+		Size:     p.Size,
+		Percent:  p.Percent(),
+		Eta:      durationpb.New(p.Eta),
+	}
+}
@@ -0,0 +1,212 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/semirm-dev/findhotel/cache"
+	"github.com/semirm-dev/findhotel/datastore"
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/semirm-dev/findhotel/importer"
+	"github.com/semirm-dev/findhotel/jobs"
+	"github.com/semirm-dev/findhotel/proto/geopb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newImportServiceClient will serve ImportService over in-memory connection, root has a single data_dump.csv file
+func newImportServiceClient(t *testing.T, newLoader jobs.NewLoaderFunc) geopb.ImportServiceClient {
+	root := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "data_dump.csv"), []byte(
+		"ip_address,country_code,country,city,latitude,longitude,mystery_value\n"+
+			"1.1.1.1,NL,Netherlands,Amsterdam,52.37,4.89,100\n"+
+			"2.2.2.2,NL,Netherlands,Amsterdam,52.37,4.89,100\n"+
+			"2.2.2.2,NL,Netherlands,Amsterdam,52.37,4.89,100\n"), 0644))
+
+	svc := jobs.NewImportService(jobs.NewManager(root, newLoader))
+
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	svc.RegisterGrpcServer(srv)
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return geopb.NewImportServiceClient(conn)
+}
+
+func csvLoader(_ context.Context, _, path string) (jobs.Loader, func(), error) {
+	ldr := geo.NewLoader(importer.NewCsvImporter(path, 10), datastore.NewInMemory(), cache.NewInMemory())
+	ldr.ProgressInterval = time.Millisecond
+
+	return ldr, func() {}, nil
+}
+
+// blockingLoader loads nothing until it's cancelled
+type blockingLoader struct{}
+
+func (blockingLoader) Load(ctx context.Context, _ int) *geo.Report {
+	<-ctx.Done()
+	return &geo.Report{}
+}
+
+func (blockingLoader) Subscribe(geo.ProgressFunc) {}
+
+func blocking(context.Context, string, string) (jobs.Loader, func(), error) {
+	return blockingLoader{}, func() {}, nil
+}
+
+// lastStatus will stream import progress and return final status
+func lastStatus(t *testing.T, client geopb.ImportServiceClient, id string) *geopb.ImportStatus {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.StreamProgress(ctx, &geopb.StreamProgressRequest{Id: id})
+	assert.Nil(t, err)
+
+	var last *geopb.ImportStatus
+	for {
+		s, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return last
+		}
+		if !assert.Nil(t, err) {
+			return last
+		}
+		last = s
+	}
+}
+
+func TestImportService_StartImport(t *testing.T) {
+	client := newImportServiceClient(t, csvLoader)
+
+	started, err := client.StartImport(context.Background(), &geopb.StartImportRequest{Path: "data_dump.csv", Workers: 2})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, started.GetId())
+	assert.Equal(t, "data_dump.csv", started.GetPath())
+
+	final := lastStatus(t, client, started.GetId())
+	assert.Equal(t, geopb.ImportState_IMPORT_STATE_COMPLETED, final.GetState())
+	assert.NotNil(t, final.GetFinishedAt())
+	assert.Equal(t, int64(3), final.GetReport().GetRead())
+	assert.Equal(t, int64(2), final.GetReport().GetStored())
+	assert.Equal(t, int64(1), final.GetReport().GetInFileDuplicates())
+	assert.Equal(t, int64(3), final.GetProgress().GetRead())
+	assert.Equal(t, int64(2), final.GetProgress().GetStored())
+
+	got, err := client.GetStatus(context.Background(), &geopb.GetStatusRequest{Id: started.GetId()})
+	assert.Nil(t, err)
+	assert.Equal(t, geopb.ImportState_IMPORT_STATE_COMPLETED, got.GetState())
+}
+
+func TestImportService_StartImport_Errors(t *testing.T) {
+	testTable := map[string]struct {
+		path         string
+		newLoader    jobs.NewLoaderFunc
+		expectedCode codes.Code
+	}{
+		"missing path": {
+			newLoader:    csvLoader,
+			expectedCode: codes.InvalidArgument,
+		},
+		"missing file": {
+			path:         "missing.csv",
+			newLoader:    csvLoader,
+			expectedCode: codes.InvalidArgument,
+		},
+		"path outside of data directory": {
+			path:         "../../data_dump.csv",
+			newLoader:    csvLoader,
+			expectedCode: codes.OK,
+		},
+		"data directory": {
+			path:         ".",
+			newLoader:    csvLoader,
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for name, suite := range testTable {
+		t.Run(name, func(t *testing.T) {
+			client := newImportServiceClient(t, suite.newLoader)
+
+			started, err := client.StartImport(context.Background(), &geopb.StartImportRequest{Path: suite.path})
+			assert.Equal(t, suite.expectedCode, status.Code(err))
+
+			// path which escapes data directory is resolved within it
+			if suite.expectedCode == codes.OK {
+				assert.Equal(t, geopb.ImportState_IMPORT_STATE_COMPLETED, lastStatus(t, client, started.GetId()).GetState())
+			}
+		})
+	}
+}
+
+func TestImportService_LoaderError(t *testing.T) {
+	client := newImportServiceClient(t, func(context.Context, string, string) (jobs.Loader, func(), error) {
+		return nil, nil, errors.New("redis unavailable")
+	})
+
+	started, err := client.StartImport(context.Background(), &geopb.StartImportRequest{Path: "data_dump.csv"})
+	assert.Nil(t, err)
+
+	final := lastStatus(t, client, started.GetId())
+	assert.Equal(t, geopb.ImportState_IMPORT_STATE_FAILED, final.GetState())
+	assert.Equal(t, "redis unavailable", final.GetError())
+	assert.Nil(t, final.GetReport())
+}
+
+func TestImportService_Cancel(t *testing.T) {
+	client := newImportServiceClient(t, blocking)
+
+	started, err := client.StartImport(context.Background(), &geopb.StartImportRequest{Path: "data_dump.csv"})
+	assert.Nil(t, err)
+	assert.Equal(t, geopb.ImportState_IMPORT_STATE_RUNNING, started.GetState())
+
+	_, err = client.StartImport(context.Background(), &geopb.StartImportRequest{Path: "data_dump.csv"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	cancelled, err := client.Cancel(context.Background(), &geopb.CancelRequest{Id: started.GetId()})
+	assert.Nil(t, err)
+	assert.Equal(t, geopb.ImportState_IMPORT_STATE_CANCELLED, cancelled.GetState())
+
+	// next import can be started once running one is finished
+	_, err = client.StartImport(context.Background(), &geopb.StartImportRequest{Path: "data_dump.csv"})
+	assert.Nil(t, err)
+}
+
+func TestImportService_NotFound(t *testing.T) {
+	client := newImportServiceClient(t, csvLoader)
+
+	_, err := client.GetStatus(context.Background(), &geopb.GetStatusRequest{Id: "unknown"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.Cancel(context.Background(), &geopb.CancelRequest{Id: "unknown"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	stream, err := client.StreamProgress(context.Background(), &geopb.StreamProgressRequest{Id: "unknown"})
+	assert.Nil(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,315 @@
+// Package jobs will run imports in background, so they can be started, watched and cancelled remotely.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrNotFound is returned for unknown import job id
+	ErrNotFound = errors.New("import job not found")
+	// ErrRunning is returned when import is started while another one is running
+	ErrRunning = errors.New("another import is running")
+	// ErrInvalidPath is returned when imported path is not a file in data directory
+	ErrInvalidPath = errors.New("invalid import path")
+)
+
+// Loader will load *geo data from a single source, it's implemented by geo loader
+type Loader interface {
+	Load(ctx context.Context, workers int) *geo.Report
+	Subscribe(fn geo.ProgressFunc)
+}
+
+// NewLoaderFunc will create Loader of import id for csv file at path, id can be used to name import output files.
+// Returned func is called once Load is finished, to release loader resources.
+type NewLoaderFunc func(ctx context.Context, id, path string) (Loader, func(), error)
+
+// defaultRetention is how long finished jobs are kept by default
+const defaultRetention = time.Hour
+
+// Job presents a single import started by manager
+type Job struct {
+	Id         string        `json:"id"`
+	Path       string        `json:"path"`
+	Status     geo.RunStatus `json:"status"`
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt *time.Time    `json:"finished_at"`
+	// Progress is the latest progress published by loader
+	Progress *geo.Progress `json:"progress"`
+	// Report is set once import is finished
+	Report *geo.Report `json:"report"`
+	// Error is set when loader could not be created
+	Error string `json:"error,omitempty"`
+}
+
+// Finished reports whether import is no longer running
+func (j *Job) Finished() bool {
+	return j.Status != geo.RunRunning
+}
+
+type job struct {
+	Job
+	cancel context.CancelFunc
+	done   chan struct{}
+	// changed is closed and replaced each time job is updated
+	changed chan struct{}
+}
+
+type manager struct {
+	root      string
+	newLoader NewLoaderFunc
+	// Workers is number of data store workers used when import is started without them
+	Workers int
+	// Retention is how long finished jobs are kept (1 hour by default), they are evicted when the next import is started
+	Retention time.Duration
+	// History (optional) is searched for status of evicted jobs, loader must save its run with job id
+	History geo.RunSearch
+	mu      sync.RWMutex
+	jobs    map[string]*job
+	running *job
+}
+
+// NewManager will initialize import jobs manager, only csv files within root directory can be imported
+func NewManager(root string, newLoader NewLoaderFunc) *manager {
+	return &manager{
+		root:      root,
+		newLoader: newLoader,
+		Workers:   5,
+		Retention: defaultRetention,
+		jobs:      make(map[string]*job),
+	}
+}
+
+// Start will start importing csv file at path (relative to root) in background
+func (m *manager) Start(path string, workers int) (*Job, error) {
+	fullPath, err := m.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if workers <= 0 {
+		workers = m.Workers
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running != nil {
+		return nil, ErrRunning
+	}
+	m.evict()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	j := &job{
+		Job: Job{
+			Id:        newId(),
+			Path:      path,
+			Status:    geo.RunRunning,
+			StartedAt: time.Now(),
+		},
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		changed: make(chan struct{}),
+	}
+	m.jobs[j.Id] = j
+	m.running = j
+
+	go m.run(ctx, j, fullPath, workers)
+
+	return j.snapshot(), nil
+}
+
+// run will load csv file and finish job with import report
+func (m *manager) run(ctx context.Context, j *job, path string, workers int) {
+	defer j.cancel()
+
+	var report *geo.Report
+	var loadErr error
+
+	ldr, closeLoader, err := m.newLoader(ctx, j.Id, path)
+	if err != nil {
+		loadErr = err
+	} else {
+		ldr.Subscribe(func(p geo.Progress) {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+
+			j.Progress = &p
+			j.notify()
+		})
+		report = ldr.Load(ctx, workers)
+		closeLoader()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	finishedAt := time.Now()
+	j.FinishedAt = &finishedAt
+	j.Report = report
+
+	switch {
+	case loadErr != nil:
+		logrus.Errorf("import %s failed to start: %v", j.Id, loadErr)
+		j.Status = geo.RunFailed
+		j.Error = loadErr.Error()
+	case ctx.Err() != nil:
+		j.Status = geo.RunCancelled
+	case report.Failed > 0:
+		j.Status = geo.RunFailed
+	default:
+		j.Status = geo.RunCompleted
+	}
+
+	logrus.Infof("import %s of %s %s", j.Id, j.Path, j.Status)
+
+	m.running = nil
+	close(j.done)
+	j.notify()
+}
+
+// evict will remove jobs finished before Retention, manager lock must be held
+func (m *manager) evict() {
+	retention := m.Retention
+	if retention <= 0 {
+		retention = defaultRetention
+	}
+
+	evictBefore := time.Now().Add(-retention)
+	for id, j := range m.jobs {
+		if j.Finished() && j.FinishedAt.Before(evictBefore) {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// Status returns current state of import job, evicted job is found in History by its run
+func (m *manager) Status(ctx context.Context, id string) (*Job, error) {
+	if j, ok := m.snapshot(id); ok {
+		return j, nil
+	}
+
+	if m.History == nil {
+		return nil, ErrNotFound
+	}
+
+	run, err := m.History.RunById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, ErrNotFound
+	}
+
+	return &Job{
+		Id:         run.Id,
+		Path:       run.Source,
+		Status:     run.Status,
+		StartedAt:  run.StartedAt,
+		FinishedAt: run.FinishedAt,
+	}, nil
+}
+
+// snapshot returns current state of job kept by manager
+func (m *manager) snapshot(id string) (*Job, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, false
+	}
+
+	return j.snapshot(), true
+}
+
+// Watch returns current state of import job and channel which is closed once job is updated,
+// e.g. with new progress or when it's finished
+func (m *manager) Watch(id string) (*Job, <-chan struct{}, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, nil, ErrNotFound
+	}
+
+	return j.snapshot(), j.changed, nil
+}
+
+// Cancel will stop running import, it doesn't wait for import to finish (see Done)
+func (m *manager) Cancel(id string) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	j.cancel()
+
+	return nil
+}
+
+// Done returns channel which is closed once import job is finished
+func (m *manager) Done(id string) (<-chan struct{}, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return j.done, nil
+}
+
+// resolve will return full path of file within root, path can't point outside of it
+func (m *manager) resolve(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("%w: path is required", ErrInvalidPath)
+	}
+
+	// cleaning rooted path removes all leading "..", so it always stays within root
+	fullPath := filepath.Join(m.root, filepath.Clean("/"+path))
+
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidPath, path)
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%w: %s is not a file", ErrInvalidPath, path)
+	}
+
+	return fullPath, nil
+}
+
+// notify will wake up job watchers, manager lock must be held
+func (j *job) notify() {
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// snapshot returns copy of job, safe to read after manager lock is released
+func (j *job) snapshot() *Job {
+	s := j.Job
+	return &s
+}
+
+func newId() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
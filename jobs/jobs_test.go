@@ -0,0 +1,79 @@
+package jobs_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/semirm-dev/findhotel/cache"
+	"github.com/semirm-dev/findhotel/datastore"
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/semirm-dev/findhotel/importer"
+	"github.com/semirm-dev/findhotel/jobs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager_Evict(t *testing.T) {
+	root := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "data_dump.csv"), []byte(
+		"ip_address,country_code,country,city,latitude,longitude,mystery_value\n"+
+			"1.1.1.1,NL,Netherlands,Amsterdam,52.37,4.89,100\n"), 0644))
+
+	history := datastore.NewInMemoryHistory()
+	manager := jobs.NewManager(root, func(_ context.Context, id, path string) (jobs.Loader, func(), error) {
+		ldr := geo.NewLoader(importer.NewCsvImporter(path, 10), datastore.NewInMemory(), cache.NewInMemory())
+		ldr.History = history
+		ldr.RunId = id
+
+		return ldr, func() {}, nil
+	})
+	manager.Retention = time.Nanosecond
+	manager.History = history
+
+	// start will run import and wait until it's finished
+	start := func() *jobs.Job {
+		j, err := manager.Start("data_dump.csv", 1)
+		assert.Nil(t, err)
+
+		done, err := manager.Done(j.Id)
+		assert.Nil(t, err)
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("import is not finished")
+		}
+
+		return j
+	}
+
+	first := start()
+	kept, err := manager.Status(context.Background(), first.Id)
+	assert.Nil(t, err)
+	assert.NotNil(t, kept.Report)
+
+	// finished job is evicted once the next one is started
+	second := start()
+	_, _, err = manager.Watch(first.Id)
+	assert.ErrorIs(t, err, jobs.ErrNotFound)
+
+	evicted, err := manager.Status(context.Background(), first.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, first.Id, evicted.Id)
+	assert.Equal(t, geo.RunCompleted, evicted.Status)
+	assert.NotNil(t, evicted.FinishedAt)
+
+	latest, err := manager.Status(context.Background(), second.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, geo.RunCompleted, latest.Status)
+
+	// without history evicted job is not found
+	manager.History = nil
+	_, err = manager.Status(context.Background(), first.Id)
+	assert.ErrorIs(t, err, jobs.ErrNotFound)
+
+	manager.History = history
+	_, err = manager.Status(context.Background(), "unknown")
+	assert.ErrorIs(t, err, jobs.ErrNotFound)
+}
@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/semirm-dev/findhotel/cache"
+	"github.com/semirm-dev/findhotel/geo"
+)
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "gateway",
+		Name:      "http_requests_total",
+		Help:      "Number of http requests by route, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "gateway",
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of http requests by route, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	searchDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "gateway",
+		Name:      "datastore_duration_seconds",
+		Help:      "Latency of data store look ups by operation (by_ip, by_ips) and result (ok, error).",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "result"})
+
+	registerGateway sync.Once
+)
+
+func registerGatewayMetrics() {
+	registerGateway.Do(func() {
+		prometheus.MustRegister(httpRequests, httpDuration, searchDuration)
+	})
+}
+
+// Gin will count requests and measure their latency, routes are labeled by their pattern (e.g. /imports/:id),
+// unknown routes are labeled as "unmatched"
+func Gin() gin.HandlerFunc {
+	registerGatewayMetrics()
+
+	return func(c *gin.Context) {
+		t := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		httpRequests.WithLabelValues(route, c.Request.Method, status).Inc()
+		httpDuration.WithLabelValues(route, c.Request.Method, status).Observe(since(t))
+	}
+}
+
+type search struct {
+	search geo.Search
+}
+
+// NewSearch will measure latency of search look ups, search is usually data store
+func NewSearch(s geo.Search) *search {
+	registerGatewayMetrics()
+
+	return &search{
+		search: s,
+	}
+}
+
+func (s *search) ByIp(ctx context.Context, ip string) (*geo.Geo, error) {
+	t := time.Now()
+	geoData, err := s.search.ByIp(ctx, ip)
+	searchDuration.WithLabelValues("by_ip", result(err)).Observe(since(t))
+
+	return geoData, err
+}
+
+func (s *search) ByIps(ctx context.Context, ips []string) (map[string]*geo.Geo, error) {
+	t := time.Now()
+	found, err := s.search.ByIps(ctx, ips)
+	searchDuration.WithLabelValues("by_ips", result(err)).Observe(since(t))
+
+	return found, err
+}
+
+// LookupStats is implemented by lookup cache
+type LookupStats interface {
+	Stats() *cache.LookupStats
+}
+
+// noLookupStats are stats of gateway without lookup cache
+type noLookupStats struct{}
+
+func (noLookupStats) Stats() *cache.LookupStats {
+	return &cache.LookupStats{}
+}
+
+// RegisterLookupStats will export hits and misses of lookup cache. Stats are nil when there is no lookup cache,
+// counters are exported anyway (always 0), so dashboards and alerts don't depend on gateway configuration.
+func RegisterLookupStats(stats LookupStats) {
+	if stats == nil {
+		stats = noLookupStats{}
+	}
+
+	prometheus.MustRegister(
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "gateway",
+			Name:      "lookup_cache_hits_total",
+			Help:      "Number of ips found in lookup cache.",
+		}, func() float64 {
+			return float64(stats.Stats().Hits)
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "gateway",
+			Name:      "lookup_cache_misses_total",
+			Help:      "Number of ips looked up in data store because they were not in lookup cache.",
+		}, func() float64 {
+			return float64(stats.Stats().Misses)
+		}),
+	)
+}
@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/semirm-dev/findhotel/geo"
+)
+
+var (
+	rowsRead = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "loader",
+		Name:      "rows_read_total",
+		Help:      "Number of rows read from imported files, including ones that failed to parse.",
+	})
+
+	rows = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "loader",
+		Name:      "rows_total",
+		Help:      "Number of read rows by outcome.",
+	}, []string{"outcome"})
+
+	storeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "loader",
+		Name:      "batch_store_duration_seconds",
+		Help:      "Latency of each attempt to store a batch by result (ok, error).",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 12),
+	}, []string{"result"})
+
+	retries = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "loader",
+		Name:      "store_retries_total",
+		Help:      "Number of re-tried attempts to store a batch.",
+	})
+
+	cacheDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "loader",
+		Name:      "cache_duration_seconds",
+		Help:      "Latency of dedup cache calls by operation (get, store) and result (ok, error).",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "result"})
+
+	registerLoader sync.Once
+)
+
+func registerLoaderMetrics() {
+	registerLoader.Do(func() {
+		prometheus.MustRegister(rowsRead, rows, storeDuration, retries, cacheDuration)
+	})
+}
+
+// ObserveProgress returns geo.ProgressFunc which counts rows by outcome while import is running,
+// each progress adds rows counted since the previous one of the same run
+func ObserveProgress() geo.ProgressFunc {
+	registerLoaderMetrics()
+
+	var prev geo.Progress
+	return func(p geo.Progress) {
+		if p.RunId != prev.RunId {
+			prev = geo.Progress{RunId: p.RunId}
+		}
+
+		rowsRead.Add(float64(p.Read - prev.Read))
+		rows.WithLabelValues("parse_error").Add(float64(p.ParseErrors - prev.ParseErrors))
+		rows.WithLabelValues("invalid").Add(float64(p.Invalid - prev.Invalid))
+		rows.WithLabelValues("in_file_duplicate").Add(float64(p.InFileDuplicates - prev.InFileDuplicates))
+		rows.WithLabelValues("duplicate").Add(float64(p.Duplicates - prev.Duplicates))
+		rows.WithLabelValues("stored").Add(float64(p.Stored - prev.Stored))
+		rows.WithLabelValues("skipped").Add(float64(p.Skipped - prev.Skipped))
+		rows.WithLabelValues("failed").Add(float64(p.Failed - prev.Failed))
+
+		prev = p
+	}
+}
+
+// ObserveRetry will count re-tried store attempt, it can be used as geo.RetryPolicy OnRetry
+func ObserveRetry(int, error) {
+	registerLoaderMetrics()
+
+	retries.Inc()
+}
+
+type storer struct {
+	storer geo.Storer
+}
+
+// NewStorer will measure latency of each batch stored by storer
+func NewStorer(s geo.Storer) *storer {
+	registerLoaderMetrics()
+
+	return &storer{
+		storer: s,
+	}
+}
+
+func (s *storer) Store(ctx context.Context, geoData []*geo.Geo, policy geo.ConflictPolicy) (int, error) {
+	t := time.Now()
+	stored, err := s.storer.Store(ctx, geoData, policy)
+	storeDuration.WithLabelValues(result(err)).Observe(since(t))
+
+	return stored, err
+}
+
+type cacheStore struct {
+	cache geo.Cache
+}
+
+// NewCache will measure latency of dedup cache calls
+func NewCache(c geo.Cache) *cacheStore {
+	registerLoaderMetrics()
+
+	return &cacheStore{
+		cache: c,
+	}
+}
+
+func (c *cacheStore) Store(ctx context.Context, items geo.CacheBucket) error {
+	t := time.Now()
+	err := c.cache.Store(ctx, items)
+	cacheDuration.WithLabelValues("store", result(err)).Observe(since(t))
+
+	return err
+}
+
+func (c *cacheStore) Get(ctx context.Context, keys []string) (geo.KeySet, error) {
+	t := time.Now()
+	existing, err := c.cache.Get(ctx, keys)
+	cacheDuration.WithLabelValues("get", result(err)).Observe(since(t))
+
+	return existing, err
+}
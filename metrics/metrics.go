@@ -0,0 +1,38 @@
+// Package metrics will export gateway and loader metrics to prometheus.
+// Collectors are registered in default prometheus registry once they are first used.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+const namespace = "findhotel"
+
+// Handler will serve all registered metrics in prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Push will push all registered metrics to prometheus pushgateway at url, grouped by job
+func Push(url, job string) error {
+	return push.New(url, job).Gatherer(prometheus.DefaultGatherer).Push()
+}
+
+// result is label value of finished operation
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+
+	return "ok"
+}
+
+// since returns seconds elapsed from t, as observed by histograms
+func since(t time.Time) float64 {
+	return time.Since(t).Seconds()
+}
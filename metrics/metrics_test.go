@@ -0,0 +1,94 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/semirm-dev/findhotel/cache"
+	"github.com/semirm-dev/findhotel/datastore"
+	"github.com/semirm-dev/findhotel/gateway"
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/semirm-dev/findhotel/importer"
+	"github.com/semirm-dev/findhotel/internal/web"
+	"github.com/semirm-dev/findhotel/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+// scrape will get all registered metrics in prometheus text format
+func scrape(t *testing.T) string {
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	return w.Body.String()
+}
+
+func TestGin(t *testing.T) {
+	searchApi := datastore.NewInMemory()
+	_, err := searchApi.Store(context.Background(), []*geo.Geo{{Ip: "1.1.1.1"}}, geo.ConflictSkip)
+	assert.Nil(t, err)
+
+	lookup := cache.NewLookup(metrics.NewSearch(searchApi), cache.NewLru(10))
+	metrics.RegisterLookupStats(lookup)
+
+	router := web.NewRouter()
+	router.Use(metrics.Gin())
+	router.NoRoute(gateway.NotFound())
+	router.GET("geo", gateway.GetGeoLocation(lookup))
+	router.GET("metrics", gin.WrapH(metrics.Handler()))
+
+	for _, path := range []string{"/geo?ip=1.1.1.1", "/geo?ip=1.1.1.1", "/geo?ip=2.2.2.2", "/unknown"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	scraped := scrape(t)
+	assert.Contains(t, scraped, `findhotel_gateway_http_requests_total{method="GET",route="/geo",status="200"} 2`)
+	assert.Contains(t, scraped, `findhotel_gateway_http_requests_total{method="GET",route="/geo",status="404"} 1`)
+	assert.Contains(t, scraped, `findhotel_gateway_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, scraped, `findhotel_gateway_http_request_duration_seconds_count{method="GET",route="/geo",status="200"} 2`)
+	assert.Contains(t, scraped, `findhotel_gateway_datastore_duration_seconds_count{operation="by_ips",result="ok"} 2`)
+	assert.Contains(t, scraped, "findhotel_gateway_lookup_cache_hits_total 1")
+	assert.Contains(t, scraped, "findhotel_gateway_lookup_cache_misses_total 2")
+}
+
+// failingStorer fails to store first batch once
+type failingStorer struct {
+	geo.Storer
+	failed bool
+}
+
+func (s *failingStorer) Store(ctx context.Context, geoData []*geo.Geo, policy geo.ConflictPolicy) (int, error) {
+	if !s.failed {
+		s.failed = true
+		return 0, errors.New("connection reset")
+	}
+
+	return s.Storer.Store(ctx, geoData, policy)
+}
+
+func TestLoader(t *testing.T) {
+	given := []*geo.Geo{{Ip: "1.1.1.1"}, {Ip: "1.1.1.1"}, {Ip: ""}, {Ip: "2.2.2.2"}}
+
+	ldr := geo.NewLoader(importer.NewInMemory(given, 10),
+		metrics.NewStorer(&failingStorer{Storer: datastore.NewInMemory()}), metrics.NewCache(cache.NewInMemory()))
+	ldr.Retry = &geo.RetryPolicy{Attempts: 2, Backoff: time.Millisecond, OnRetry: metrics.ObserveRetry}
+	ldr.Subscribe(metrics.ObserveProgress())
+
+	ldr.Load(context.Background(), 1)
+
+	scraped := scrape(t)
+	assert.Contains(t, scraped, "findhotel_loader_rows_read_total 4")
+	assert.Contains(t, scraped, `findhotel_loader_rows_total{outcome="stored"} 2`)
+	assert.Contains(t, scraped, `findhotel_loader_rows_total{outcome="invalid"} 1`)
+	assert.Contains(t, scraped, `findhotel_loader_rows_total{outcome="in_file_duplicate"} 1`)
+	assert.Contains(t, scraped, `findhotel_loader_batch_store_duration_seconds_count{result="error"} 1`)
+	assert.Contains(t, scraped, `findhotel_loader_batch_store_duration_seconds_count{result="ok"} 1`)
+	assert.Contains(t, scraped, "findhotel_loader_store_retries_total 1")
+	assert.Contains(t, scraped, `findhotel_loader_cache_duration_seconds_count{operation="get",result="ok"} 1`)
+	assert.Contains(t, scraped, `findhotel_loader_cache_duration_seconds_count{operation="store",result="ok"} 1`)
+}
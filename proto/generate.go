@@ -0,0 +1,5 @@
+// Package proto holds protobuf definitions of grpc services, generated code is in sub-packages.
+// It requires buf, protoc-gen-go and protoc-gen-go-grpc in PATH.
+package proto
+
+//go:generate buf generate
@@ -0,0 +1,218 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: geo.proto
+
+package geopb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// GeoServiceClient is the client API for GeoService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type GeoServiceClient interface {
+	// Lookup returns NOT_FOUND status for unknown ip and INVALID_ARGUMENT for invalid one
+	Lookup(ctx context.Context, in *LookupRequest, opts ...grpc.CallOption) (*LookupResponse, error)
+	// BatchLookup returns results keyed by requested ip, unknown ips are not found results
+	BatchLookup(ctx context.Context, in *BatchLookupRequest, opts ...grpc.CallOption) (*BatchLookupResponse, error)
+	// StreamLookup returns a result for each requested ip, in the same order.
+	// Invalid ip doesn't end the stream, its result has error set instead.
+	StreamLookup(ctx context.Context, opts ...grpc.CallOption) (GeoService_StreamLookupClient, error)
+}
+
+type geoServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGeoServiceClient(cc grpc.ClientConnInterface) GeoServiceClient {
+	return &geoServiceClient{cc}
+}
+
+func (c *geoServiceClient) Lookup(ctx context.Context, in *LookupRequest, opts ...grpc.CallOption) (*LookupResponse, error) {
+	out := new(LookupResponse)
+	err := c.cc.Invoke(ctx, "/findhotel.geo.v1.GeoService/Lookup", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *geoServiceClient) BatchLookup(ctx context.Context, in *BatchLookupRequest, opts ...grpc.CallOption) (*BatchLookupResponse, error) {
+	out := new(BatchLookupResponse)
+	err := c.cc.Invoke(ctx, "/findhotel.geo.v1.GeoService/BatchLookup", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *geoServiceClient) StreamLookup(ctx context.Context, opts ...grpc.CallOption) (GeoService_StreamLookupClient, error) {
+	stream, err := c.cc.NewStream(ctx, &GeoService_ServiceDesc.Streams[0], "/findhotel.geo.v1.GeoService/StreamLookup", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &geoServiceStreamLookupClient{stream}
+	return x, nil
+}
+
+type GeoService_StreamLookupClient interface {
+	Send(*LookupRequest) error
+	Recv() (*LookupResponse, error)
+	grpc.ClientStream
+}
+
+type geoServiceStreamLookupClient struct {
+	grpc.ClientStream
+}
+
+func (x *geoServiceStreamLookupClient) Send(m *LookupRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *geoServiceStreamLookupClient) Recv() (*LookupResponse, error) {
+	m := new(LookupResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// GeoServiceServer is the server API for GeoService service.
+// All implementations must embed UnimplementedGeoServiceServer
+// for forward compatibility
+type GeoServiceServer interface {
+	// Lookup returns NOT_FOUND status for unknown ip and INVALID_ARGUMENT for invalid one
+	Lookup(context.Context, *LookupRequest) (*LookupResponse, error)
+	// BatchLookup returns results keyed by requested ip, unknown ips are not found results
+	BatchLookup(context.Context, *BatchLookupRequest) (*BatchLookupResponse, error)
+	// StreamLookup returns a result for each requested ip, in the same order.
+	// Invalid ip doesn't end the stream, its result has error set instead.
+	StreamLookup(GeoService_StreamLookupServer) error
+	mustEmbedUnimplementedGeoServiceServer()
+}
+
+// UnimplementedGeoServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedGeoServiceServer struct {
+}
+
+func (UnimplementedGeoServiceServer) Lookup(context.Context, *LookupRequest) (*LookupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Lookup not implemented")
+}
+func (UnimplementedGeoServiceServer) BatchLookup(context.Context, *BatchLookupRequest) (*BatchLookupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchLookup not implemented")
+}
+func (UnimplementedGeoServiceServer) StreamLookup(GeoService_StreamLookupServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamLookup not implemented")
+}
+func (UnimplementedGeoServiceServer) mustEmbedUnimplementedGeoServiceServer() {}
+
+// UnsafeGeoServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GeoServiceServer will
+// result in compilation errors.
+type UnsafeGeoServiceServer interface {
+	mustEmbedUnimplementedGeoServiceServer()
+}
+
+func RegisterGeoServiceServer(s grpc.ServiceRegistrar, srv GeoServiceServer) {
+	s.RegisterService(&GeoService_ServiceDesc, srv)
+}
+
+func _GeoService_Lookup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GeoServiceServer).Lookup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/findhotel.geo.v1.GeoService/Lookup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GeoServiceServer).Lookup(ctx, req.(*LookupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GeoService_BatchLookup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchLookupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GeoServiceServer).BatchLookup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/findhotel.geo.v1.GeoService/BatchLookup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GeoServiceServer).BatchLookup(ctx, req.(*BatchLookupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GeoService_StreamLookup_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GeoServiceServer).StreamLookup(&geoServiceStreamLookupServer{stream})
+}
+
+type GeoService_StreamLookupServer interface {
+	Send(*LookupResponse) error
+	Recv() (*LookupRequest, error)
+	grpc.ServerStream
+}
+
+type geoServiceStreamLookupServer struct {
+	grpc.ServerStream
+}
+
+func (x *geoServiceStreamLookupServer) Send(m *LookupResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *geoServiceStreamLookupServer) Recv() (*LookupRequest, error) {
+	m := new(LookupRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// GeoService_ServiceDesc is the grpc.ServiceDesc for GeoService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GeoService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "findhotel.geo.v1.GeoService",
+	HandlerType: (*GeoServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Lookup",
+			Handler:    _GeoService_Lookup_Handler,
+		},
+		{
+			MethodName: "BatchLookup",
+			Handler:    _GeoService_BatchLookup_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamLookup",
+			Handler:       _GeoService_StreamLookup_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "geo.proto",
+}
@@ -0,0 +1,251 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: import.proto
+
+package geopb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ImportServiceClient is the client API for ImportService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ImportServiceClient interface {
+	// StartImport returns INVALID_ARGUMENT for path which is not a file in loader data directory
+	// and FAILED_PRECONDITION if another import is running
+	StartImport(ctx context.Context, in *StartImportRequest, opts ...grpc.CallOption) (*ImportStatus, error)
+	// GetStatus returns NOT_FOUND status for unknown import id
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*ImportStatus, error)
+	// Cancel will stop running import and wait for it to finish, finished import is returned as it is
+	Cancel(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*ImportStatus, error)
+	// StreamProgress sends import status with each loader progress until import is finished, the last one is final
+	StreamProgress(ctx context.Context, in *StreamProgressRequest, opts ...grpc.CallOption) (ImportService_StreamProgressClient, error)
+}
+
+type importServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewImportServiceClient(cc grpc.ClientConnInterface) ImportServiceClient {
+	return &importServiceClient{cc}
+}
+
+func (c *importServiceClient) StartImport(ctx context.Context, in *StartImportRequest, opts ...grpc.CallOption) (*ImportStatus, error) {
+	out := new(ImportStatus)
+	err := c.cc.Invoke(ctx, "/findhotel.geo.v1.ImportService/StartImport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *importServiceClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*ImportStatus, error) {
+	out := new(ImportStatus)
+	err := c.cc.Invoke(ctx, "/findhotel.geo.v1.ImportService/GetStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *importServiceClient) Cancel(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*ImportStatus, error) {
+	out := new(ImportStatus)
+	err := c.cc.Invoke(ctx, "/findhotel.geo.v1.ImportService/Cancel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *importServiceClient) StreamProgress(ctx context.Context, in *StreamProgressRequest, opts ...grpc.CallOption) (ImportService_StreamProgressClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ImportService_ServiceDesc.Streams[0], "/findhotel.geo.v1.ImportService/StreamProgress", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &importServiceStreamProgressClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ImportService_StreamProgressClient interface {
+	Recv() (*ImportStatus, error)
+	grpc.ClientStream
+}
+
+type importServiceStreamProgressClient struct {
+	grpc.ClientStream
+}
+
+func (x *importServiceStreamProgressClient) Recv() (*ImportStatus, error) {
+	m := new(ImportStatus)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ImportServiceServer is the server API for ImportService service.
+// All implementations must embed UnimplementedImportServiceServer
+// for forward compatibility
+type ImportServiceServer interface {
+	// StartImport returns INVALID_ARGUMENT for path which is not a file in loader data directory
+	// and FAILED_PRECONDITION if another import is running
+	StartImport(context.Context, *StartImportRequest) (*ImportStatus, error)
+	// GetStatus returns NOT_FOUND status for unknown import id
+	GetStatus(context.Context, *GetStatusRequest) (*ImportStatus, error)
+	// Cancel will stop running import and wait for it to finish, finished import is returned as it is
+	Cancel(context.Context, *CancelRequest) (*ImportStatus, error)
+	// StreamProgress sends import status with each loader progress until import is finished, the last one is final
+	StreamProgress(*StreamProgressRequest, ImportService_StreamProgressServer) error
+	mustEmbedUnimplementedImportServiceServer()
+}
+
+// UnimplementedImportServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedImportServiceServer struct {
+}
+
+func (UnimplementedImportServiceServer) StartImport(context.Context, *StartImportRequest) (*ImportStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartImport not implemented")
+}
+func (UnimplementedImportServiceServer) GetStatus(context.Context, *GetStatusRequest) (*ImportStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedImportServiceServer) Cancel(context.Context, *CancelRequest) (*ImportStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Cancel not implemented")
+}
+func (UnimplementedImportServiceServer) StreamProgress(*StreamProgressRequest, ImportService_StreamProgressServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamProgress not implemented")
+}
+func (UnimplementedImportServiceServer) mustEmbedUnimplementedImportServiceServer() {}
+
+// UnsafeImportServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ImportServiceServer will
+// result in compilation errors.
+type UnsafeImportServiceServer interface {
+	mustEmbedUnimplementedImportServiceServer()
+}
+
+func RegisterImportServiceServer(s grpc.ServiceRegistrar, srv ImportServiceServer) {
+	s.RegisterService(&ImportService_ServiceDesc, srv)
+}
+
+func _ImportService_StartImport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartImportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImportServiceServer).StartImport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/findhotel.geo.v1.ImportService/StartImport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImportServiceServer).StartImport(ctx, req.(*StartImportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImportService_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImportServiceServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/findhotel.geo.v1.ImportService/GetStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImportServiceServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImportService_Cancel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImportServiceServer).Cancel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/findhotel.geo.v1.ImportService/Cancel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImportServiceServer).Cancel(ctx, req.(*CancelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImportService_StreamProgress_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamProgressRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ImportServiceServer).StreamProgress(m, &importServiceStreamProgressServer{stream})
+}
+
+type ImportService_StreamProgressServer interface {
+	Send(*ImportStatus) error
+	grpc.ServerStream
+}
+
+type importServiceStreamProgressServer struct {
+	grpc.ServerStream
+}
+
+func (x *importServiceStreamProgressServer) Send(m *ImportStatus) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// ImportService_ServiceDesc is the grpc.ServiceDesc for ImportService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ImportService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "findhotel.geo.v1.ImportService",
+	HandlerType: (*ImportServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "StartImport",
+			Handler:    _ImportService_StartImport_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _ImportService_GetStatus_Handler,
+		},
+		{
+			MethodName: "Cancel",
+			Handler:    _ImportService_Cancel_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamProgress",
+			Handler:       _ImportService_StreamProgress_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "import.proto",
+}
@@ -0,0 +1,68 @@
+package rejects
+
+import (
+	"encoding/csv"
+	"github.com/semirm-dev/findhotel/geo"
+	"os"
+	"strconv"
+	"sync"
+)
+
+var header = []string{"line", "reason", "error", "fields"}
+
+type csvRejects struct {
+	mu   sync.Mutex
+	file *os.File
+	w    *csv.Writer
+}
+
+// NewCsv will create (or truncate) csv file at path and write rejected rows into it.
+// Each record holds line number, reason, error and then all raw fields of rejected row.
+func NewCsv(path string) (*csvRejects, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := csv.NewWriter(f)
+	if err = w.Write(header); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &csvRejects{
+		file: f,
+		w:    w,
+	}, nil
+}
+
+func (r *csvRejects) Reject(rejection *geo.Rejection) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record := []string{strconv.Itoa(rejection.Line), string(rejection.Reason), errString(rejection.Err)}
+
+	return r.w.Write(append(record, rejection.Fields...))
+}
+
+// Close will flush and close underlying csv file
+func (r *csvRejects) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.w.Flush()
+	if err := r.w.Error(); err != nil {
+		r.file.Close()
+		return err
+	}
+
+	return r.file.Close()
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
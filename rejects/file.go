@@ -0,0 +1,25 @@
+package rejects
+
+import (
+	"github.com/semirm-dev/findhotel/geo"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// File is geo.Rejecter backed by a file, it must be closed when import is finished
+type File interface {
+	geo.Rejecter
+	io.Closer
+}
+
+// NewFile will create rejects file at path, format is chosen by file extension:
+// .jsonl or .json for json lines, csv otherwise
+func NewFile(path string) (File, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".json":
+		return NewJsonl(path)
+	default:
+		return NewCsv(path)
+	}
+}
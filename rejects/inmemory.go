@@ -0,0 +1,31 @@
+package rejects
+
+import (
+	"github.com/semirm-dev/findhotel/geo"
+	"sync"
+)
+
+type inmemory struct {
+	mu         sync.Mutex
+	rejections []*geo.Rejection
+}
+
+func NewInMemory() *inmemory {
+	return &inmemory{}
+}
+
+func (r *inmemory) Reject(rejection *geo.Rejection) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rejections = append(r.rejections, rejection)
+
+	return nil
+}
+
+func (r *inmemory) All() []*geo.Rejection {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.rejections
+}
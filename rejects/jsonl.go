@@ -0,0 +1,60 @@
+package rejects
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/semirm-dev/findhotel/geo"
+	"os"
+	"sync"
+)
+
+type jsonlRejects struct {
+	mu   sync.Mutex
+	file *os.File
+	w    *bufio.Writer
+	enc  *json.Encoder
+}
+
+type jsonlRecord struct {
+	*geo.Rejection
+	Error string `json:"error,omitempty"`
+}
+
+// NewJsonl will create (or truncate) file at path and write rejected rows into it, one json object per line
+func NewJsonl(path string) (*jsonlRejects, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := bufio.NewWriter(f)
+
+	return &jsonlRejects{
+		file: f,
+		w:    w,
+		enc:  json.NewEncoder(w),
+	}, nil
+}
+
+func (r *jsonlRejects) Reject(rejection *geo.Rejection) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.enc.Encode(&jsonlRecord{
+		Rejection: rejection,
+		Error:     errString(rejection.Err),
+	})
+}
+
+// Close will flush and close underlying file
+func (r *jsonlRejects) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.w.Flush(); err != nil {
+		r.file.Close()
+		return err
+	}
+
+	return r.file.Close()
+}
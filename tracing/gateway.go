@@ -0,0 +1,116 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/semirm-dev/findhotel/cache"
+	"github.com/semirm-dev/findhotel/geo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Gin will start server span for each request, continuing trace propagated in request headers (traceparent).
+// Spans are named by route pattern (e.g. GET /imports/:id), unknown routes are named "unmatched".
+func Gin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		ctx, span := otel.Tracer(instrumentation).Start(ctx, fmt.Sprintf("%s %s", c.Request.Method, route),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPMethodKey.String(c.Request.Method),
+				semconv.HTTPRouteKey.String(route),
+				semconv.HTTPTargetKey.String(c.Request.URL.RequestURI()),
+			))
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		if len(c.Errors) > 0 {
+			span.RecordError(c.Errors.Last())
+		}
+	}
+}
+
+type search struct {
+	name   string
+	search geo.Search
+}
+
+// NewSearch will start span for each search look up, spans are named by search (e.g. datastore.ByIp)
+func NewSearch(name string, s geo.Search) *search {
+	return &search{
+		name:   name,
+		search: s,
+	}
+}
+
+func (s *search) ByIp(ctx context.Context, ip string) (*geo.Geo, error) {
+	ctx, span := Start(ctx, s.name+".ByIp", attribute.String("geo.ip", ip))
+	geoData, err := s.search.ByIp(ctx, ip)
+	span.SetAttributes(attribute.Bool("geo.found", geoData != nil))
+	End(span, err)
+
+	return geoData, err
+}
+
+func (s *search) ByIps(ctx context.Context, ips []string) (map[string]*geo.Geo, error) {
+	ctx, span := Start(ctx, s.name+".ByIps", attribute.Int("geo.ips", len(ips)))
+	found, err := s.search.ByIps(ctx, ips)
+	span.SetAttributes(attribute.Int("geo.found", len(found)))
+	End(span, err)
+
+	return found, err
+}
+
+type lookupBackend struct {
+	backend cache.LookupBackend
+}
+
+// NewLookupBackend will start span for each round-trip to lookup cache backend
+func NewLookupBackend(b cache.LookupBackend) *lookupBackend {
+	return &lookupBackend{
+		backend: b,
+	}
+}
+
+func (b *lookupBackend) Get(ctx context.Context, ips []string) (map[string]*geo.Geo, error) {
+	ctx, span := Start(ctx, "lookup_cache.Get", attribute.Int("geo.ips", len(ips)))
+	cached, err := b.backend.Get(ctx, ips)
+	span.SetAttributes(attribute.Int("cache.hits", len(cached)))
+	End(span, err)
+
+	return cached, err
+}
+
+func (b *lookupBackend) Set(ctx context.Context, results map[string]*geo.Geo, ttl time.Duration) error {
+	ctx, span := Start(ctx, "lookup_cache.Set", attribute.Int("geo.ips", len(results)))
+	err := b.backend.Set(ctx, results, ttl)
+	End(span, err)
+
+	return err
+}
+
+func (b *lookupBackend) Invalidate() error {
+	return b.backend.Invalidate()
+}
@@ -0,0 +1,88 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// gormSpan is key of span kept in gorm statement between before and after callbacks
+const gormSpan = "tracing:span"
+
+type gormPlugin struct{}
+
+// NewGormPlugin will start client span for each query executed by gorm, spans are named by operation (e.g. gorm.create).
+// Register it with db.Use, spans are children of span in ctx given with db.WithContext.
+func NewGormPlugin() gorm.Plugin {
+	return &gormPlugin{}
+}
+
+func (p *gormPlugin) Name() string {
+	return "tracing"
+}
+
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	for _, reg := range []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", callbacks.Create().Before("gorm:create").Register, callbacks.Create().After("gorm:create").Register},
+		{"query", callbacks.Query().Before("gorm:query").Register, callbacks.Query().After("gorm:query").Register},
+		{"update", callbacks.Update().Before("gorm:update").Register, callbacks.Update().After("gorm:update").Register},
+		{"delete", callbacks.Delete().Before("gorm:delete").Register, callbacks.Delete().After("gorm:delete").Register},
+		{"row", callbacks.Row().Before("gorm:row").Register, callbacks.Row().After("gorm:row").Register},
+		{"raw", callbacks.Raw().Before("gorm:raw").Register, callbacks.Raw().After("gorm:raw").Register},
+	} {
+		if err := reg.before("tracing:before_"+reg.operation, startGorm(reg.operation)); err != nil {
+			return err
+		}
+		if err := reg.after("tracing:after_"+reg.operation, endGorm); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// startGorm will start span of gorm operation, statement continues with span in its ctx
+func startGorm(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		ctx, span := otel.Tracer(instrumentation).Start(tx.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperationKey.String(operation)))
+
+		tx.Statement.Context = ctx
+		tx.InstanceSet(gormSpan, span)
+	}
+}
+
+// endGorm will end span of gorm operation with executed sql, record not found is not an error
+func endGorm(tx *gorm.DB) {
+	v, ok := tx.InstanceGet(gormSpan)
+	if !ok {
+		return
+	}
+	span, ok := v.(trace.Span)
+	if !ok {
+		return
+	}
+
+	span.SetAttributes(
+		semconv.DBStatementKey.String(tx.Statement.SQL.String()),
+		semconv.DBSQLTableKey.String(tx.Statement.Table),
+		attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+	)
+
+	err := tx.Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	End(span, err)
+}
@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// metadataCarrier will let propagator read trace context from grpc incoming metadata
+type metadataCarrier metadata.MD
+
+func (md metadataCarrier) Get(key string) string {
+	values := metadata.MD(md).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+func (md metadataCarrier) Set(key, value string) {
+	metadata.MD(md).Set(key, value)
+}
+
+func (md metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(md))
+	for k := range md {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+// startRpc will start server span for grpc method, continuing trace propagated in incoming metadata
+func startRpc(ctx context.Context, method string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+
+	return otel.Tracer(instrumentation).Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(semconv.RPCSystemKey.String("grpc")))
+}
+
+// endRpc will end server span with grpc status code of finished call
+func endRpc(span trace.Span, err error) {
+	code := status.Code(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(code)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, code.String())
+	}
+
+	span.End()
+}
+
+// UnaryServerInterceptor will start server span for each unary grpc call
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, span := startRpc(ctx, info.FullMethod)
+		resp, err := handler(ctx, req)
+		endRpc(span, err)
+
+		return resp, err
+	}
+}
+
+// tracedStream carries server span context to stream handler
+type tracedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tracedStream) Context() context.Context {
+	return s.ctx
+}
+
+// StreamServerInterceptor will start server span for each grpc stream, span lasts until stream is finished
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := startRpc(ss.Context(), info.FullMethod)
+		err := handler(srv, &tracedStream{ServerStream: ss, ctx: ctx})
+		endRpc(span, err)
+
+		return err
+	}
+}
+
+// GrpcServerOptions will start server span for each grpc call, unary and stream
+func GrpcServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(UnaryServerInterceptor()),
+		grpc.StreamInterceptor(StreamServerInterceptor()),
+	}
+}
@@ -0,0 +1,58 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/semirm-dev/findhotel/geo"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type storer struct {
+	storer geo.Storer
+}
+
+// NewStorer will start span for each batch stored by storer, re-tried batch has span per attempt
+func NewStorer(s geo.Storer) *storer {
+	return &storer{
+		storer: s,
+	}
+}
+
+func (s *storer) Store(ctx context.Context, geoData []*geo.Geo, policy geo.ConflictPolicy) (int, error) {
+	ctx, span := Start(ctx, "storer.Store",
+		attribute.Int("geo.batch_size", len(geoData)),
+		attribute.String("geo.conflict", policy.String()))
+	stored, err := s.storer.Store(ctx, geoData, policy)
+	span.SetAttributes(attribute.Int("geo.stored", stored))
+	End(span, err)
+
+	return stored, err
+}
+
+type cacheStore struct {
+	cache geo.Cache
+}
+
+// NewCache will start span for each round-trip to dedup cache
+func NewCache(c geo.Cache) *cacheStore {
+	return &cacheStore{
+		cache: c,
+	}
+}
+
+func (c *cacheStore) Store(ctx context.Context, items geo.CacheBucket) error {
+	ctx, span := Start(ctx, "cache.Store", attribute.Int("cache.items", len(items)))
+	err := c.cache.Store(ctx, items)
+	End(span, err)
+
+	return err
+}
+
+func (c *cacheStore) Get(ctx context.Context, keys []string) (geo.KeySet, error) {
+	ctx, span := Start(ctx, "cache.Get", attribute.Int("cache.keys", len(keys)))
+	existing, err := c.cache.Get(ctx, keys)
+	span.SetAttributes(attribute.Int("cache.hits", len(existing)))
+	End(span, err)
+
+	return existing, err
+}
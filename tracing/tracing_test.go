@@ -0,0 +1,152 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/semirm-dev/findhotel/cache"
+	"github.com/semirm-dev/findhotel/datastore"
+	"github.com/semirm-dev/findhotel/gateway"
+	"github.com/semirm-dev/findhotel/geo"
+	"github.com/semirm-dev/findhotel/importer"
+	"github.com/semirm-dev/findhotel/internal/web"
+	"github.com/semirm-dev/findhotel/tracing"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// record will collect all spans ended by global tracer provider
+func record(t *testing.T) *tracetest.SpanRecorder {
+	_, err := tracing.Init(context.Background(), "test", "none", "")
+	assert.Nil(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	return recorder
+}
+
+// spansByName will get ended spans keyed by their name
+func spansByName(recorder *tracetest.SpanRecorder) map[string]sdktrace.ReadOnlySpan {
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, s := range recorder.Ended() {
+		spans[s.Name()] = s
+	}
+
+	return spans
+}
+
+func TestGin(t *testing.T) {
+	recorder := record(t)
+
+	searchApi := datastore.NewInMemory()
+	_, err := searchApi.Store(context.Background(), []*geo.Geo{{Ip: "1.1.1.1"}}, geo.ConflictSkip)
+	assert.Nil(t, err)
+
+	lookup := cache.NewLookup(tracing.NewSearch("datastore", searchApi), tracing.NewLookupBackend(cache.NewLru(10)))
+
+	router := web.NewRouter()
+	router.Use(tracing.Gin())
+	router.GET("geo", gateway.GetGeoLocation(tracing.NewSearch("lookup", lookup)))
+
+	req := httptest.NewRequest("GET", "/geo?ip=1.1.1.1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	spans := spansByName(recorder)
+	assert.Len(t, spans, 5)
+
+	server := spans["GET /geo"]
+	assert.NotNil(t, server)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+
+	assert.Equal(t, server.SpanContext().SpanID(), spans["lookup.ByIp"].Parent().SpanID())
+	for _, name := range []string{"lookup_cache.Get", "datastore.ByIps", "lookup_cache.Set"} {
+		assert.Equal(t, spans["lookup.ByIp"].SpanContext().SpanID(), spans[name].Parent().SpanID(), name)
+		assert.Equal(t, server.SpanContext().TraceID(), spans[name].SpanContext().TraceID(), name)
+	}
+}
+
+// failingStorer fails to store every batch after the first one
+type failingStorer struct {
+	calls int
+}
+
+func (s *failingStorer) Store(_ context.Context, geoData []*geo.Geo, _ geo.ConflictPolicy) (int, error) {
+	s.calls++
+	if s.calls > 1 {
+		return 0, errors.New("connection reset")
+	}
+
+	return len(geoData), nil
+}
+
+func TestLoader(t *testing.T) {
+	recorder := record(t)
+
+	given := []*geo.Geo{{Ip: "1.1.1.1"}, {Ip: "2.2.2.2"}}
+
+	ldr := geo.NewLoader(importer.NewInMemory(given, 1), tracing.NewStorer(&failingStorer{}), tracing.NewCache(cache.NewInMemory()))
+	ldr.Retry = &geo.RetryPolicy{Attempts: 1}
+
+	ctx, parent := tracing.Start(context.Background(), "import")
+	ldr.Load(ctx, 1)
+	tracing.End(parent, nil)
+
+	spans := spansByName(recorder)
+	for _, name := range []string{"cache.Get", "cache.Store", "storer.Store"} {
+		assert.Equal(t, parent.SpanContext().SpanID(), spans[name].Parent().SpanID(), name)
+	}
+
+	// the last stored batch failed
+	assert.Equal(t, codes.Error, spans["storer.Store"].Status().Code)
+	assert.Equal(t, codes.Unset, spans["cache.Get"].Status().Code)
+}
+
+type traced struct {
+	Id int
+	Ip string
+}
+
+func TestGormPlugin(t *testing.T) {
+	recorder := record(t)
+
+	// dry run builds sql without connecting to postgres, default transaction would connect
+	db, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	assert.Nil(t, err)
+	assert.Nil(t, db.Use(tracing.NewGormPlugin()))
+	assert.ErrorIs(t, db.Use(tracing.NewGormPlugin()), gorm.ErrRegistered)
+
+	ctx, parent := tracing.Start(context.Background(), "import")
+	db.WithContext(ctx).Create(&traced{Ip: "1.1.1.1"})
+	db.WithContext(ctx).Where("ip = ?", "1.1.1.1").Find(&[]*traced{})
+	tracing.End(parent, nil)
+
+	spans := spansByName(recorder)
+	for name, statement := range map[string]string{
+		"gorm.create": `INSERT INTO "traceds" ("ip") VALUES ($1) RETURNING "id"`,
+		"gorm.query":  `SELECT * FROM "traceds" WHERE ip = $1`,
+	} {
+		span := spans[name]
+		if !assert.NotNil(t, span, name) {
+			continue
+		}
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID(), name)
+		assert.Equal(t, trace.SpanKindClient, span.SpanKind(), name)
+		assert.Contains(t, span.Attributes(), semconv.DBStatementKey.String(statement), name)
+		assert.Contains(t, span.Attributes(), semconv.DBSQLTableKey.String("traceds"), name)
+	}
+}